@@ -0,0 +1,390 @@
+package api
+
+import (
+	"context"
+	"expvar"
+	"fmt"
+	"net/http"
+	"net/http/pprof"
+	"reflect"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// SensitiveFields are the words of the field names redacted from the settings, e.g. key
+	// matches api_key and APIKey but not api_keys
+	SensitiveFields = []string{"password", "secret", "token", "key", "credential", "credentials", "hash"}
+
+	// Redacted replaces the sensitive values
+	Redacted = "[REDACTED]"
+
+	credentialsPattern = regexp.MustCompile(`(://[^:/@]+):[^@/]+@`)
+)
+
+// AdminConfig ...
+type AdminConfig struct {
+	Host string `yaml:"host"`
+}
+
+// AdminServer exposes pprof, expvar, the redacted settings, build info, the log level,
+// metrics and health on a listener that shouldn't be reachable from the public network.
+type AdminServer struct {
+	Engine *gin.Engine
+
+	Host      string
+	Logger    *logrus.Logger
+	Metrics   http.Handler
+	Healthz   gin.HandlerFunc
+	Liveness  gin.HandlerFunc
+	Readiness gin.HandlerFunc
+	Settings  interface{}
+}
+
+// AdminOption ...
+type AdminOption func(*AdminServer)
+
+// BuildInfo ...
+type BuildInfo struct {
+	GoVersion string        `json:"go_version"`
+	Path      string        `json:"path,omitempty"`
+	Version   string        `json:"version,omitempty"`
+	Sum       string        `json:"sum,omitempty"`
+	Deps      []BuildModule `json:"deps,omitempty"`
+}
+
+// BuildModule ...
+type BuildModule struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+	Sum     string `json:"sum,omitempty"`
+}
+
+type logLevel struct {
+	Level string `json:"level" binding:"required"`
+}
+
+// WithAdminLogger sets the logger whose level can be changed at runtime
+func WithAdminLogger(logger *logrus.Logger) AdminOption {
+	return func(s *AdminServer) {
+		s.Logger = logger
+	}
+}
+
+// WithAdminMetrics ...
+func WithAdminMetrics(metrics http.Handler) AdminOption {
+	return func(s *AdminServer) {
+		s.Metrics = metrics
+	}
+}
+
+// WithAdminHealthz ...
+func WithAdminHealthz(healthz gin.HandlerFunc) AdminOption {
+	return func(s *AdminServer) {
+		s.Healthz = healthz
+	}
+}
+
+// WithAdminLiveness ...
+func WithAdminLiveness(liveness gin.HandlerFunc) AdminOption {
+	return func(s *AdminServer) {
+		s.Liveness = liveness
+	}
+}
+
+// WithAdminReadiness ...
+func WithAdminReadiness(readiness gin.HandlerFunc) AdminOption {
+	return func(s *AdminServer) {
+		s.Readiness = readiness
+	}
+}
+
+// WithAdminSettings exposes the effective configuration with the sensitive values redacted
+func WithAdminSettings(settings interface{}) AdminOption {
+	return func(s *AdminServer) {
+		s.Settings = settings
+	}
+}
+
+// NewAdminServer ...
+func NewAdminServer(host string, options ...AdminOption) *AdminServer {
+	server := &AdminServer{
+		Host:      host,
+		Logger:    logrus.StandardLogger(),
+		Metrics:   promhttp.Handler(),
+		Healthz:   DefaultHealthz,
+		Liveness:  DefaultHealthz,
+		Readiness: DefaultHealthz,
+	}
+
+	for _, o := range options {
+		o(server)
+	}
+
+	server.Engine = gin.New()
+	server.Engine.Use(Recovery(server.Logger, prometheus.DefaultRegisterer))
+
+	server.Engine.GET("healthz", server.Healthz)
+	server.Engine.GET("livez", server.Liveness)
+	server.Engine.GET("readyz", server.Readiness)
+	server.Engine.GET("metrics", gin.WrapH(server.Metrics))
+
+	server.Engine.GET("debug/vars", gin.WrapH(expvar.Handler()))
+	server.Engine.GET("debug/pprof/*profile", profile)
+	server.Engine.POST("debug/pprof/symbol", gin.WrapF(pprof.Symbol))
+
+	server.Engine.GET("settings", server.settings)
+	server.Engine.GET("buildinfo", buildInfo)
+	server.Engine.GET("loglevel", server.getLogLevel)
+	server.Engine.PUT("loglevel", server.setLogLevel)
+
+	return server
+}
+
+// Run serves the admin routes until ctx is cancelled
+func (s *AdminServer) Run(ctx context.Context) error {
+	srv := &http.Server{
+		Addr:    s.Host,
+		Handler: s.Engine,
+	}
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-done:
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), DefaultShutdownTimeout)
+		defer cancel()
+
+		srv.Shutdown(ctx)
+	}()
+
+	s.Logger.WithField("addr", s.Host).Info("starting admin server")
+
+	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return err
+	}
+
+	return nil
+}
+
+func (s *AdminServer) settings(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, redact(reflect.ValueOf(s.Settings), false))
+}
+
+func (s *AdminServer) getLogLevel(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, &logLevel{Level: s.Logger.GetLevel().String()})
+}
+
+func (s *AdminServer) setLogLevel(ctx *gin.Context) {
+	req := new(logLevel)
+
+	if !BindJSON(ctx, req) {
+		return
+	}
+
+	level, err := logrus.ParseLevel(req.Level)
+
+	if err != nil {
+		BindingError(ctx, err)
+		return
+	}
+
+	s.Logger.WithField("level", level.String()).Warn("changing log level")
+	s.Logger.SetLevel(level)
+
+	ctx.JSON(http.StatusOK, &logLevel{Level: level.String()})
+}
+
+func profile(ctx *gin.Context) {
+	switch strings.TrimPrefix(ctx.Param("profile"), "/") {
+	case "cmdline":
+		pprof.Cmdline(ctx.Writer, ctx.Request)
+	case "profile":
+		pprof.Profile(ctx.Writer, ctx.Request)
+	case "symbol":
+		pprof.Symbol(ctx.Writer, ctx.Request)
+	case "trace":
+		pprof.Trace(ctx.Writer, ctx.Request)
+	default:
+		pprof.Index(ctx.Writer, ctx.Request)
+	}
+}
+
+func buildInfo(ctx *gin.Context) {
+	info := &BuildInfo{GoVersion: runtime.Version()}
+
+	if build, ok := debug.ReadBuildInfo(); ok {
+		info.Path = build.Main.Path
+		info.Version = build.Main.Version
+		info.Sum = build.Main.Sum
+
+		for _, dep := range build.Deps {
+			info.Deps = append(info.Deps, BuildModule{
+				Path:    dep.Path,
+				Version: dep.Version,
+				Sum:     dep.Sum,
+			})
+		}
+	}
+
+	ctx.JSON(http.StatusOK, info)
+}
+
+// redact converts the value into maps keyed by the yaml field names, replacing the
+// sensitive fields, everything nested in them and the passwords of connection strings
+func redact(value reflect.Value, sensitive bool) interface{} {
+	for value.Kind() == reflect.Ptr || value.Kind() == reflect.Interface {
+		if value.IsNil() {
+			return nil
+		}
+
+		value = value.Elem()
+	}
+
+	if !value.IsValid() {
+		return nil
+	}
+
+	if value.Type() == reflect.TypeOf(time.Duration(0)) {
+		return time.Duration(value.Int()).String()
+	}
+
+	if value.Type() == reflect.TypeOf(time.Time{}) {
+		return value.Interface().(time.Time).Format(time.RFC3339Nano)
+	}
+
+	switch value.Kind() {
+	case reflect.Struct:
+		result := make(map[string]interface{})
+
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+
+			if field.PkgPath != "" {
+				continue
+			}
+
+			tag := strings.Split(field.Tag.Get("yaml"), ",")
+			name := tag[0]
+
+			if name == "-" {
+				continue
+			}
+
+			if contains(tag[1:], "inline") {
+				inline, _ := redact(value.Field(i), sensitive).(map[string]interface{})
+
+				for key, value := range inline {
+					result[key] = value
+				}
+
+				continue
+			}
+
+			words := name
+
+			if name == "" {
+				words = field.Name
+				name = strings.ToLower(field.Name)
+			}
+
+			result[name] = redact(value.Field(i), sensitive || isSensitive(words))
+		}
+
+		return result
+	case reflect.Map:
+		result := make(map[string]interface{})
+
+		for _, key := range value.MapKeys() {
+			name := fmt.Sprint(key.Interface())
+			result[name] = redact(value.MapIndex(key), sensitive || isSensitive(name))
+		}
+
+		return result
+	case reflect.Slice, reflect.Array:
+		if value.Type().Elem().Kind() == reflect.Uint8 && sensitive {
+			return Redacted
+		}
+
+		result := make([]interface{}, value.Len())
+
+		for i := range result {
+			result[i] = redact(value.Index(i), sensitive)
+		}
+
+		return result
+	case reflect.String:
+		if sensitive && value.String() != "" {
+			return Redacted
+		}
+
+		return credentialsPattern.ReplaceAllString(value.String(), "$1:"+Redacted+"@")
+	default:
+		if sensitive {
+			return Redacted
+		}
+
+		return value.Interface()
+	}
+}
+
+func isSensitive(name string) bool {
+	for _, word := range nameWords(name) {
+		if contains(SensitiveFields, word) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// nameWords splits snake, kebab, dotted and camel case names into lower case words
+func nameWords(name string) []string {
+	words := []string{}
+	runes := []rune(name)
+	start := 0
+
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if i > start {
+				words = append(words, string(runes[start:i]))
+			}
+
+			start = i + 1
+			continue
+		}
+
+		// a new word starts at an upper case letter after a lower case one, or before
+		// the last letter of an acronym followed by a lower case one, e.g. API|Key
+		if i > start && unicode.IsUpper(r) && (unicode.IsLower(runes[i-1]) ||
+			i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+
+	if start < len(runes) {
+		words = append(words, string(runes[start:]))
+	}
+
+	for i := range words {
+		words[i] = strings.ToLower(words[i])
+	}
+
+	return words
+}
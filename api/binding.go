@@ -0,0 +1,67 @@
+package api
+
+import (
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// registers the json field names before any binding or error mapping runs
+func init() {
+	validate, ok := binding.Validator.Engine().(*validator.Validate)
+
+	if ok {
+		validate.RegisterTagNameFunc(fieldName)
+	}
+}
+
+// The Bind helpers validate the bound struct, respond with the binding error
+// and return false when either step fails.
+
+// BindJSON binds the request body into obj
+func BindJSON(ctx *gin.Context, obj interface{}) bool {
+	return bind(ctx, obj, binding.JSON)
+}
+
+// BindQuery binds the query string into obj
+func BindQuery(ctx *gin.Context, obj interface{}) bool {
+	return bind(ctx, obj, binding.Query)
+}
+
+// BindURI binds the route params into obj
+func BindURI(ctx *gin.Context, obj interface{}) bool {
+	if err := ctx.ShouldBindUri(obj); err != nil {
+		BindingError(ctx, err)
+		return false
+	}
+
+	return true
+}
+
+func bind(ctx *gin.Context, obj interface{}, b binding.Binding) bool {
+	if err := ctx.ShouldBindWith(obj, b); err != nil {
+		BindingError(ctx, err)
+		return false
+	}
+
+	return true
+}
+
+func fieldName(field reflect.StructField) string {
+	for _, tag := range []string{"json", "form", "uri"} {
+		name := strings.Split(field.Tag.Get(tag), ",")[0]
+
+		if name == "-" {
+			return ""
+		}
+
+		if name != "" {
+			return name
+		}
+	}
+
+	return field.Name
+}
@@ -0,0 +1,116 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raafvargas/wrapit/api"
+	"github.com/raafvargas/wrapit/contract"
+	"github.com/stretchr/testify/assert"
+)
+
+type bindingRequest struct {
+	Name  string `json:"name" form:"name" binding:"required"`
+	Limit int    `json:"limit" form:"limit" binding:"max=10"`
+}
+
+type bindingURI struct {
+	ID string `uri:"id" binding:"uuid"`
+}
+
+func bindingEngine() *gin.Engine {
+	engine := gin.New()
+
+	engine.POST("json", func(ctx *gin.Context) {
+		req := new(bindingRequest)
+		if !api.BindJSON(ctx, req) {
+			return
+		}
+		ctx.Status(http.StatusNoContent)
+	})
+	engine.GET("query", func(ctx *gin.Context) {
+		req := new(bindingRequest)
+		if !api.BindQuery(ctx, req) {
+			return
+		}
+		ctx.Status(http.StatusNoContent)
+	})
+	engine.GET("uri/:id", func(ctx *gin.Context) {
+		req := new(bindingURI)
+		if !api.BindURI(ctx, req) {
+			return
+		}
+		ctx.Status(http.StatusNoContent)
+	})
+
+	return engine
+}
+
+func TestBindJSON(t *testing.T) {
+	res := httptest.NewRecorder()
+	bindingEngine().ServeHTTP(res, httptest.NewRequest(http.MethodPost, "/json", strings.NewReader(`{"name":"a"}`)))
+
+	assert.Equal(t, http.StatusNoContent, res.Code)
+}
+
+func TestBindJSONValidation(t *testing.T) {
+	res := httptest.NewRecorder()
+	bindingEngine().ServeHTTP(res, httptest.NewRequest(http.MethodPost, "/json", strings.NewReader(`{"limit":20}`)))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, res.Code)
+
+	body := new(contract.Error)
+	assert.NoError(t, json.Unmarshal(res.Body.Bytes(), body))
+	assert.Len(t, body.Details, 2)
+	assert.Equal(t, "name", body.Details[0].Field)
+	assert.Equal(t, "required", body.Details[0].Tag)
+	assert.Equal(t, "limit", body.Details[1].Field)
+	assert.Equal(t, "10", body.Details[1].Param)
+	assert.Equal(t, float64(20), body.Details[1].Value)
+}
+
+func TestBindJSONMalformed(t *testing.T) {
+	res := httptest.NewRecorder()
+	bindingEngine().ServeHTTP(res, httptest.NewRequest(http.MethodPost, "/json", strings.NewReader(`{`)))
+
+	assert.Equal(t, http.StatusBadRequest, res.Code)
+}
+
+func TestBindQuery(t *testing.T) {
+	res := httptest.NewRecorder()
+	bindingEngine().ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/query?limit=1", nil))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, res.Code)
+
+	res = httptest.NewRecorder()
+	bindingEngine().ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/query?name=a", nil))
+
+	assert.Equal(t, http.StatusNoContent, res.Code)
+}
+
+func TestBindURI(t *testing.T) {
+	res := httptest.NewRecorder()
+	bindingEngine().ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/uri/invalid", nil))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, res.Code)
+}
+
+func TestBindingErrorJSONFieldNames(t *testing.T) {
+	engine := gin.New()
+	engine.POST("json", func(ctx *gin.Context) {
+		if err := ctx.ShouldBindJSON(new(bindingRequest)); err != nil {
+			api.BindingError(ctx, err)
+		}
+	})
+
+	res := httptest.NewRecorder()
+	engine.ServeHTTP(res, httptest.NewRequest(http.MethodPost, "/json", strings.NewReader(`{}`)))
+
+	body := new(contract.Error)
+	assert.NoError(t, json.Unmarshal(res.Body.Bytes(), body))
+	assert.Equal(t, "name", body.Details[0].Field)
+}
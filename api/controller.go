@@ -1,10 +1,11 @@
 package api
 
 import (
+	"errors"
 	"net/http"
-	"reflect"
 
 	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
 	"github.com/raafvargas/wrapit/contract"
 )
 
@@ -16,20 +17,52 @@ type Controller interface {
 //BindingError ...
 func BindingError(context *gin.Context, err error) {
 	context.Error(err)
-	context.JSON(http.StatusBadRequest, contract.NewError(http.StatusBadRequest, err.Error()))
+
+	message := contract.NewError(http.StatusBadRequest, err.Error())
+
+	if _, ok := err.(validator.ValidationErrors); ok {
+		message = contract.FromValidationError(err)
+	}
+
+	if errors.Is(err, ErrRequestBodyTooLarge) {
+		message = requestBodyTooLarge()
+	}
+
+	if wantsProblem(context) {
+		AbortWithProblem(context, NewProblem(context, message))
+		return
+	}
+
+	if message.Code != http.StatusBadRequest {
+		context.AbortWithStatusJSON(message.Code, message)
+		return
+	}
+
+	context.JSON(message.Code, message)
 }
 
 // ResolveError ...
 func ResolveError(ctx *gin.Context, err error) {
 	ctx.Error(err)
 
-	if reflect.TypeOf(err) != reflect.TypeOf(&contract.Error{}) {
+	if wantsProblem(ctx) {
+		AbortWithProblem(ctx, NewProblem(ctx, err))
+		return
+	}
+
+	var message *contract.Error
+
+	if !errors.As(err, &message) {
+		if problem, ok := LookupProblem(err); ok {
+			ctx.AbortWithStatusJSON(problem.Status, contract.NewError(problem.Status, problem.Title))
+			return
+		}
+
 		ctx.AbortWithStatus(http.StatusInternalServerError)
 		return
 	}
 
 	status := http.StatusBadRequest
-	message := err.(*contract.Error)
 
 	if message.Code != 0 {
 		status = message.Code
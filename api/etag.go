@@ -0,0 +1,179 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raafvargas/wrapit/contract"
+)
+
+// ETag returns a strong ETag hashing the body
+func ETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// VersionETag returns the ETag of a document version
+func VersionETag(version int64) string {
+	return `"v` + strconv.FormatInt(version, 10) + `"`
+}
+
+// ParseVersionETag returns the document version of an ETag built by VersionETag.
+// Weak ETags are rejected, If-Match uses the strong comparison.
+func ParseVersionETag(etag string) (int64, bool) {
+	etag = strings.TrimSpace(etag)
+
+	if !strings.HasPrefix(etag, `"v`) || !strings.HasSuffix(etag, `"`) || len(etag) < 3 {
+		return 0, false
+	}
+
+	version, err := strconv.ParseInt(etag[2:len(etag)-1], 10, 64)
+
+	return version, err == nil
+}
+
+// NotModified sets the ETag header and responds 304 when it matches If-None-Match.
+// It returns true when the response was sent.
+func NotModified(ctx *gin.Context, etag string) bool {
+	ctx.Header("ETag", etag)
+
+	if !matchETag(ctx.GetHeader("If-None-Match"), etag, true) {
+		return false
+	}
+
+	ctx.AbortWithStatus(http.StatusNotModified)
+	return true
+}
+
+// IfMatch responds 412 when the If-Match header doesn't match the current ETag.
+// It returns true when the request may proceed.
+func IfMatch(ctx *gin.Context, etag string) bool {
+	header := ctx.GetHeader("If-Match")
+
+	if header == "" || matchETag(header, etag, false) {
+		return true
+	}
+
+	ResolveError(ctx, contract.NewError(http.StatusPreconditionFailed, "resource was modified"))
+	return false
+}
+
+// IfMatchVersion returns the document version of the If-Match header, responding 428
+// when it's missing and 412 when it isn't a version ETag. It returns false when it responded.
+func IfMatchVersion(ctx *gin.Context) (int64, bool) {
+	header := ctx.GetHeader("If-Match")
+
+	if header == "" {
+		ResolveError(ctx, contract.NewError(http.StatusPreconditionRequired, "If-Match header is required"))
+		return 0, false
+	}
+
+	version, ok := ParseVersionETag(header)
+
+	if !ok {
+		ResolveError(ctx, contract.NewError(http.StatusPreconditionFailed, "resource was modified"))
+		return 0, false
+	}
+
+	return version, true
+}
+
+// ConditionalGet buffers successful GET responses to set a hashed ETag, unless the
+// handler set one, and responds 304 when it matches If-None-Match.
+// It must not be used on streaming routes.
+func ConditionalGet() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if ctx.Request.Method != http.MethodGet && ctx.Request.Method != http.MethodHead {
+			ctx.Next()
+			return
+		}
+
+		writer := &bufferedWriter{ResponseWriter: ctx.Writer, body: new(bytes.Buffer)}
+		ctx.Writer = writer
+
+		ctx.Next()
+
+		ctx.Writer = writer.ResponseWriter
+
+		if ctx.Writer.Written() || writer.Status() != http.StatusOK {
+			writer.flush()
+			return
+		}
+
+		etag := writer.Header().Get("ETag")
+
+		if etag == "" {
+			etag = ETag(writer.body.Bytes())
+			writer.Header().Set("ETag", etag)
+		}
+
+		if matchETag(ctx.GetHeader("If-None-Match"), etag, true) {
+			writer.Header().Del("Content-Type")
+			writer.Header().Del("Content-Length")
+			writer.ResponseWriter.WriteHeader(http.StatusNotModified)
+			writer.ResponseWriter.WriteHeaderNow()
+			return
+		}
+
+		writer.flush()
+	}
+}
+
+// bufferedWriter holds the body until the handlers finish
+type bufferedWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *bufferedWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *bufferedWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+func (w *bufferedWriter) WriteHeaderNow() {}
+
+func (w *bufferedWriter) Written() bool {
+	return false
+}
+
+func (w *bufferedWriter) flush() {
+	w.ResponseWriter.WriteHeaderNow()
+	w.ResponseWriter.Write(w.body.Bytes())
+}
+
+// matchETag compares the header list against the etag, weakly for If-None-Match
+func matchETag(header, etag string, weak bool) bool {
+	if header == "" {
+		return false
+	}
+
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+
+	if weak {
+		etag = strings.TrimPrefix(etag, "W/")
+	}
+
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+
+		if weak {
+			candidate = strings.TrimPrefix(candidate, "W/")
+		}
+
+		if candidate == etag {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,120 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raafvargas/wrapit/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func etagEngine() *gin.Engine {
+	engine := gin.New()
+	engine.Use(api.ConditionalGet())
+
+	engine.GET("hashed", func(ctx *gin.Context) {
+		ctx.JSON(http.StatusOK, gin.H{"id": "1"})
+	})
+	engine.GET("versioned", func(ctx *gin.Context) {
+		if api.NotModified(ctx, api.VersionETag(3)) {
+			return
+		}
+		ctx.JSON(http.StatusOK, gin.H{"id": "1"})
+	})
+	engine.GET("missing", func(ctx *gin.Context) {
+		ctx.JSON(http.StatusNotFound, gin.H{})
+	})
+	engine.PUT("versioned", func(ctx *gin.Context) {
+		if !api.IfMatch(ctx, api.VersionETag(3)) {
+			return
+		}
+		ctx.Status(http.StatusNoContent)
+	})
+	engine.PATCH("versioned", func(ctx *gin.Context) {
+		version, ok := api.IfMatchVersion(ctx)
+		if !ok {
+			return
+		}
+		ctx.JSON(http.StatusOK, gin.H{"version": version})
+	})
+
+	return engine
+}
+
+func etagRequest(method, path string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	res := httptest.NewRecorder()
+	etagEngine().ServeHTTP(res, req)
+
+	return res
+}
+
+func TestConditionalGet(t *testing.T) {
+	res := etagRequest(http.MethodGet, "/hashed", nil)
+
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.JSONEq(t, `{"id":"1"}`, res.Body.String())
+
+	etag := res.Header().Get("ETag")
+	assert.Equal(t, api.ETag([]byte(`{"id":"1"}`)), etag)
+
+	res = etagRequest(http.MethodGet, "/hashed", map[string]string{"If-None-Match": `"other", ` + etag})
+
+	assert.Equal(t, http.StatusNotModified, res.Code)
+	assert.Empty(t, res.Body.String())
+	assert.Equal(t, etag, res.Header().Get("ETag"))
+}
+
+func TestConditionalGetSkipsErrors(t *testing.T) {
+	res := etagRequest(http.MethodGet, "/missing", map[string]string{"If-None-Match": "*"})
+
+	assert.Equal(t, http.StatusNotFound, res.Code)
+	assert.Empty(t, res.Header().Get("ETag"))
+}
+
+func TestNotModified(t *testing.T) {
+	res := etagRequest(http.MethodGet, "/versioned", nil)
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, `"v3"`, res.Header().Get("ETag"))
+
+	res = etagRequest(http.MethodGet, "/versioned", map[string]string{"If-None-Match": `W/"v3"`})
+	assert.Equal(t, http.StatusNotModified, res.Code)
+}
+
+func TestIfMatch(t *testing.T) {
+	assert.Equal(t, http.StatusNoContent, etagRequest(http.MethodPut, "/versioned", nil).Code)
+	assert.Equal(t, http.StatusNoContent,
+		etagRequest(http.MethodPut, "/versioned", map[string]string{"If-Match": `"v3"`}).Code)
+	assert.Equal(t, http.StatusPreconditionFailed,
+		etagRequest(http.MethodPut, "/versioned", map[string]string{"If-Match": `"v2"`}).Code)
+}
+
+func TestIfMatchVersion(t *testing.T) {
+	res := etagRequest(http.MethodPatch, "/versioned", map[string]string{"If-Match": `"v7"`})
+	assert.JSONEq(t, `{"version":7}`, res.Body.String())
+
+	assert.Equal(t, http.StatusPreconditionRequired, etagRequest(http.MethodPatch, "/versioned", nil).Code)
+	assert.Equal(t, http.StatusPreconditionFailed,
+		etagRequest(http.MethodPatch, "/versioned", map[string]string{"If-Match": `"abc"`}).Code)
+	assert.Equal(t, http.StatusPreconditionFailed,
+		etagRequest(http.MethodPatch, "/versioned", map[string]string{"If-Match": `W/"v7"`}).Code)
+}
+
+func TestParseVersionETag(t *testing.T) {
+	version, ok := api.ParseVersionETag(api.VersionETag(42))
+	assert.True(t, ok)
+	assert.Equal(t, int64(42), version)
+
+	_, ok = api.ParseVersionETag(`"42"`)
+	assert.False(t, ok)
+
+	_, ok = api.ParseVersionETag(`W/"v42"`)
+	assert.False(t, ok)
+}
@@ -0,0 +1,248 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Group mounts controllers under a prefix with their own middleware, such as auth or rate limits.
+// A group with a Version can also be reached without the prefix through version negotiation.
+type Group struct {
+	Prefix      string
+	Version     string
+	Middleware  []gin.HandlerFunc
+	Controllers []Controller
+	Deprecation *Deprecation
+}
+
+// Deprecation is announced through the Deprecation, Sunset and Link headers
+type Deprecation struct {
+	// Date is when the group was deprecated, zero for an undated deprecation
+	Date time.Time
+	// Sunset is when the group stops working
+	Sunset time.Time
+	// Link documents the deprecation, such as a migration guide
+	Link string
+}
+
+// Versioning resolves the version of requests without a version prefix from the Header,
+// or from the Accept media type, e.g. application/vnd.acme.v2+json for the MediaType
+// application/vnd.acme, falling back to the Default version.
+type Versioning struct {
+	Header    string
+	MediaType string
+	Default   string
+}
+
+// NewVersion mounts the controllers under /version
+func NewVersion(version string, controllers ...Controller) *Group {
+	return &Group{
+		Prefix:      "/" + version,
+		Version:     version,
+		Controllers: controllers,
+	}
+}
+
+// Deprecated sets the deprecation headers
+func Deprecated(deprecation *Deprecation) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if deprecation.Date.IsZero() {
+			ctx.Header("Deprecation", "true")
+		} else {
+			ctx.Header("Deprecation", fmt.Sprintf("@%d", deprecation.Date.Unix()))
+		}
+
+		if !deprecation.Sunset.IsZero() {
+			ctx.Header("Sunset", deprecation.Sunset.UTC().Format(http.TimeFormat))
+		}
+
+		if deprecation.Link != "" {
+			ctx.Writer.Header().Add("Link", fmt.Sprintf(`<%s>; rel="deprecation"`, deprecation.Link))
+		}
+
+		ctx.Next()
+	}
+}
+
+func (g *Group) register(engine *gin.Engine) {
+	handlers := []gin.HandlerFunc{}
+
+	if g.Deprecation != nil {
+		handlers = append(handlers, Deprecated(g.Deprecation))
+	}
+
+	router := engine.Group(g.Prefix, append(handlers, g.Middleware...)...)
+
+	for _, ctrl := range g.Controllers {
+		ctrl.RegisterRoutes(router)
+	}
+}
+
+func (g *Group) describe() []RouteDescription {
+	routes := []RouteDescription{}
+
+	for _, ctrl := range g.Controllers {
+		described, ok := ctrl.(DescribedController)
+
+		if !ok {
+			continue
+		}
+
+		for _, route := range described.Describe() {
+			route.Path = joinPaths(g.Prefix, route.Path)
+			route.Deprecated = route.Deprecated || g.Deprecation != nil
+			routes = append(routes, route)
+		}
+	}
+
+	return routes
+}
+
+// versionRouter rewrites requests without a version prefix to the negotiated version group,
+// unless they match a route outside the versioned groups such as /healthz
+type versionRouter struct {
+	engine     *gin.Engine
+	versioning *Versioning
+	groups     []*Group
+	mediaType  *regexp.Regexp
+
+	once   *sync.Once
+	routes []string
+}
+
+func newVersionRouter(engine *gin.Engine, versioning *Versioning, groups []*Group) *versionRouter {
+	router := &versionRouter{
+		engine:     engine,
+		versioning: versioning,
+		once:       new(sync.Once),
+	}
+
+	for _, group := range groups {
+		if group.Version != "" {
+			router.groups = append(router.groups, group)
+		}
+	}
+
+	if versioning.MediaType != "" {
+		router.mediaType = regexp.MustCompile(regexp.QuoteMeta(versioning.MediaType) + `\.([^+;,\s]+)`)
+	}
+
+	return router
+}
+
+func (r *versionRouter) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if r.versioning.Header != "" {
+		w.Header().Add("Vary", r.versioning.Header)
+	}
+
+	if r.mediaType != nil {
+		w.Header().Add("Vary", "Accept")
+	}
+
+	if group := r.resolve(req); group != nil {
+		req.URL.Path = joinPaths(group.Prefix, req.URL.Path)
+		req.URL.RawPath = ""
+
+		if r.versioning.Header != "" {
+			w.Header().Set(r.versioning.Header, group.Version)
+		}
+	}
+
+	r.engine.ServeHTTP(w, req)
+}
+
+func (r *versionRouter) resolve(req *http.Request) *Group {
+	for _, group := range r.groups {
+		if hasPrefix(req.URL.Path, group.Prefix) {
+			return nil
+		}
+	}
+
+	r.once.Do(r.loadRoutes)
+
+	for _, route := range r.routes {
+		if matchRoute(route, req.URL.Path) {
+			return nil
+		}
+	}
+
+	version := r.versioning.Default
+
+	if r.versioning.Header != "" && req.Header.Get(r.versioning.Header) != "" {
+		version = req.Header.Get(r.versioning.Header)
+	} else if r.mediaType != nil {
+		if match := r.mediaType.FindStringSubmatch(req.Header.Get("Accept")); match != nil {
+			version = match[1]
+		}
+	}
+
+	for _, group := range r.groups {
+		if group.Version == version {
+			return group
+		}
+	}
+
+	return nil
+}
+
+// loadRoutes keeps the routes outside the versioned groups
+func (r *versionRouter) loadRoutes() {
+	for _, route := range r.engine.Routes() {
+		versioned := false
+
+		for _, group := range r.groups {
+			versioned = versioned || hasPrefix(route.Path, group.Prefix)
+		}
+
+		if !versioned {
+			r.routes = append(r.routes, route.Path)
+		}
+	}
+}
+
+// LogRoutes logs the registered routes, meant for startup logs
+func (server *Server) LogRoutes() {
+	for _, route := range server.Engine.Routes() {
+		server.Logger.WithField("method", route.Method).
+			WithField("path", route.Path).
+			WithField("handler", route.Handler).
+			Info("route registered")
+	}
+}
+
+func joinPaths(prefix, path string) string {
+	return strings.TrimSuffix(prefix, "/") + "/" + strings.TrimPrefix(path, "/")
+}
+
+func hasPrefix(path, prefix string) bool {
+	prefix = strings.TrimSuffix(prefix, "/")
+	return path == prefix || strings.HasPrefix(path, prefix+"/")
+}
+
+// matchRoute matches the path against a gin route pattern with :params and *wildcards
+func matchRoute(pattern, path string) bool {
+	patterns := strings.Split(strings.Trim(pattern, "/"), "/")
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	for i, p := range patterns {
+		if strings.HasPrefix(p, "*") {
+			return true
+		}
+
+		if i >= len(segments) {
+			return false
+		}
+
+		if !strings.HasPrefix(p, ":") && p != segments[i] {
+			return false
+		}
+	}
+
+	return len(patterns) == len(segments)
+}
@@ -0,0 +1,131 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raafvargas/wrapit/api"
+	"github.com/stretchr/testify/assert"
+)
+
+type versionController struct {
+	version string
+}
+
+func (c *versionController) RegisterRoutes(router *gin.RouterGroup) {
+	router.GET("orders/:id", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, c.version+" "+ctx.Param("id"))
+	})
+}
+
+func (c *versionController) Describe() []api.RouteDescription {
+	return []api.RouteDescription{{Method: http.MethodGet, Path: "orders/:id"}}
+}
+
+func versionedServer() *api.Server {
+	sunset := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	v1 := api.NewVersion("v1", &versionController{version: "v1"})
+	v1.Deprecation = &api.Deprecation{
+		Date:   time.Unix(1700000000, 0),
+		Sunset: sunset,
+		Link:   "https://example.com/migrate",
+	}
+
+	v2 := api.NewVersion("v2", &versionController{version: "v2"})
+	v2.Middleware = []gin.HandlerFunc{func(ctx *gin.Context) {
+		ctx.Header("X-Group", "v2")
+	}}
+
+	return api.New(
+		api.WithGroup(v1),
+		api.WithGroup(v2),
+		api.WithVersioning(&api.Versioning{
+			Header:    "API-Version",
+			MediaType: "application/vnd.acme",
+			Default:   "v2",
+		}),
+		api.WithOpenAPI("/docs", api.OpenAPIInfo{Title: "test"}),
+	)
+}
+
+func serve(server *api.Server, path string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, path, nil)
+
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	res := httptest.NewRecorder()
+	server.ServeHTTP(res, req)
+
+	return res
+}
+
+func TestGroupPrefix(t *testing.T) {
+	server := versionedServer()
+
+	res := serve(server, "/v2/orders/1", nil)
+	assert.Equal(t, "v2 1", res.Body.String())
+	assert.Equal(t, "v2", res.Header().Get("X-Group"))
+	assert.Empty(t, res.Header().Get("Deprecation"))
+
+	res = serve(server, "/v1/orders/1", nil)
+	assert.Equal(t, "v1 1", res.Body.String())
+	assert.Empty(t, res.Header().Get("X-Group"))
+}
+
+func TestGroupDeprecation(t *testing.T) {
+	res := serve(versionedServer(), "/v1/orders/1", nil)
+
+	assert.Equal(t, "@1700000000", res.Header().Get("Deprecation"))
+	assert.Equal(t, "Tue, 01 Jan 2030 00:00:00 GMT", res.Header().Get("Sunset"))
+	assert.Equal(t, `<https://example.com/migrate>; rel="deprecation"`, res.Header().Get("Link"))
+}
+
+func TestVersioningHeader(t *testing.T) {
+	res := serve(versionedServer(), "/orders/1", map[string]string{"API-Version": "v1"})
+
+	assert.Equal(t, "v1 1", res.Body.String())
+	assert.Equal(t, "v1", res.Header().Get("API-Version"))
+	assert.Contains(t, res.Header().Values("Vary"), "API-Version")
+}
+
+func TestVersioningMediaType(t *testing.T) {
+	res := serve(versionedServer(), "/orders/1", map[string]string{"Accept": "application/vnd.acme.v1+json"})
+	assert.Equal(t, "v1 1", res.Body.String())
+}
+
+func TestVersioningDefault(t *testing.T) {
+	res := serve(versionedServer(), "/orders/1", nil)
+	assert.Equal(t, "v2 1", res.Body.String())
+}
+
+func TestVersioningUnknownVersion(t *testing.T) {
+	res := serve(versionedServer(), "/orders/1", map[string]string{"API-Version": "v9"})
+	assert.Equal(t, http.StatusNotFound, res.Code)
+}
+
+func TestVersioningSkipsRootRoutes(t *testing.T) {
+	server := versionedServer()
+
+	assert.Equal(t, http.StatusOK, serve(server, "/healthz", map[string]string{"API-Version": "v1"}).Code)
+	assert.Equal(t, http.StatusOK, serve(server, "/docs/openapi.json", nil).Code)
+}
+
+func TestGroupOpenAPI(t *testing.T) {
+	server := versionedServer()
+
+	assert.Contains(t, server.OpenAPI.Paths, "/v1/orders/{id}")
+	assert.Contains(t, server.OpenAPI.Paths, "/v2/orders/{id}")
+	assert.True(t, server.OpenAPI.Paths["/v1/orders/{id}"]["get"].Deprecated)
+	assert.False(t, server.OpenAPI.Paths["/v2/orders/{id}"]["get"].Deprecated)
+}
+
+func TestLogRoutes(t *testing.T) {
+	server := versionedServer()
+	assert.NotPanics(t, server.LogRoutes)
+}
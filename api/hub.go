@@ -0,0 +1,259 @@
+package api
+
+import (
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	// DefaultHubBufferSize ...
+	DefaultHubBufferSize = 64
+
+	// DefaultHubHistorySize ...
+	DefaultHubHistorySize = 256
+
+	// DefaultHubHeartbeat ...
+	DefaultHubHeartbeat = 15 * time.Second
+)
+
+// Message is pushed to the clients subscribed to its topic
+type Message struct {
+	ID    string          `json:"id"`
+	Topic string          `json:"topic"`
+	Event string          `json:"event,omitempty"`
+	Data  json.RawMessage `json:"data"`
+}
+
+// Hub fans out messages to the subscribed SSE and WebSocket clients.
+// It keeps the last messages so reconnecting clients can resume from their last event id.
+// Clients whose buffer is full are dropped and expected to reconnect and resume.
+// The history lives in the process, ids are prefixed with the hub epoch so clients
+// reconnecting to another replica or after a restart start over instead of resuming.
+type Hub struct {
+	mutex    *sync.Mutex
+	clients  map[*HubClient]struct{}
+	history  []*Message
+	epoch    string
+	sequence uint64
+	closed   bool
+
+	BufferSize  int
+	HistorySize int
+	Heartbeat   time.Duration
+}
+
+// HubOption ...
+type HubOption func(*Hub)
+
+// WithHubBufferSize sets how many messages each client can have pending
+func WithHubBufferSize(size int) HubOption {
+	return func(h *Hub) {
+		h.BufferSize = size
+	}
+}
+
+// WithHubHistorySize sets how many messages are kept for resuming clients
+func WithHubHistorySize(size int) HubOption {
+	return func(h *Hub) {
+		h.HistorySize = size
+	}
+}
+
+// WithHubHeartbeat sets the interval of SSE comments and WebSocket pings keeping idle connections open.
+// Zero or a negative interval disables them.
+func WithHubHeartbeat(interval time.Duration) HubOption {
+	return func(h *Hub) {
+		h.Heartbeat = interval
+	}
+}
+
+// NewHub ...
+func NewHub(options ...HubOption) *Hub {
+	hub := &Hub{
+		mutex:       new(sync.Mutex),
+		clients:     make(map[*HubClient]struct{}),
+		epoch:       strconv.FormatInt(time.Now().UnixNano(), 36),
+		BufferSize:  DefaultHubBufferSize,
+		HistorySize: DefaultHubHistorySize,
+		Heartbeat:   DefaultHubHeartbeat,
+	}
+
+	for _, o := range options {
+		o(hub)
+	}
+
+	return hub
+}
+
+// Publish marshals data as JSON and broadcasts it to the topic subscribers
+func (h *Hub) Publish(topic, event string, data interface{}) error {
+	body, err := json.Marshal(data)
+
+	if err != nil {
+		return err
+	}
+
+	h.Broadcast(&Message{Topic: topic, Event: event, Data: body})
+
+	return nil
+}
+
+// Broadcast sends the message to the topic subscribers, assigning its id
+func (h *Hub) Broadcast(message *Message) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.closed {
+		return
+	}
+
+	h.sequence++
+	message.ID = h.epoch + "-" + strconv.FormatUint(h.sequence, 10)
+
+	if h.HistorySize > 0 {
+		h.history = append(h.history, message)
+
+		if len(h.history) > h.HistorySize {
+			h.history = h.history[len(h.history)-h.HistorySize:]
+		}
+	}
+
+	for client := range h.clients {
+		if !client.subscribed(message.Topic) {
+			continue
+		}
+
+		select {
+		case client.messages <- message:
+		default:
+			h.drop(client)
+		}
+	}
+}
+
+// Subscribe registers a client for the given topics, or every topic when none is given.
+// Messages published after lastEventID that are still in the history are replayed.
+func (h *Hub) Subscribe(lastEventID string, topics ...string) *HubClient {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	client := &HubClient{topics: make(map[string]struct{})}
+
+	for _, topic := range topics {
+		client.topics[topic] = struct{}{}
+	}
+
+	replay := h.replay(client, lastEventID)
+	size := h.BufferSize
+
+	if len(replay) > size {
+		size = len(replay)
+	}
+
+	client.messages = make(chan *Message, size)
+
+	for _, message := range replay {
+		client.messages <- message
+	}
+
+	if h.closed {
+		close(client.messages)
+		return client
+	}
+
+	h.clients[client] = struct{}{}
+
+	return client
+}
+
+// Unsubscribe removes the client, closing its messages channel
+func (h *Hub) Unsubscribe(client *HubClient) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if _, ok := h.clients[client]; ok {
+		h.drop(client)
+	}
+}
+
+// Clients returns the number of subscribed clients
+func (h *Hub) Clients() int {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	return len(h.clients)
+}
+
+// Close disconnects every client and stops accepting messages
+func (h *Hub) Close() {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.closed = true
+
+	for client := range h.clients {
+		h.drop(client)
+	}
+}
+
+// heartbeat ticks every Heartbeat interval, or never when it isn't positive
+func (h *Hub) heartbeat() (<-chan time.Time, func()) {
+	if h.Heartbeat <= 0 {
+		return nil, func() {}
+	}
+
+	ticker := time.NewTicker(h.Heartbeat)
+
+	return ticker.C, ticker.Stop
+}
+
+func (h *Hub) drop(client *HubClient) {
+	delete(h.clients, client)
+	close(client.messages)
+}
+
+func (h *Hub) replay(client *HubClient, lastEventID string) []*Message {
+	if lastEventID == "" {
+		return nil
+	}
+
+	for i, message := range h.history {
+		if message.ID != lastEventID {
+			continue
+		}
+
+		replay := []*Message{}
+
+		for _, message := range h.history[i+1:] {
+			if client.subscribed(message.Topic) {
+				replay = append(replay, message)
+			}
+		}
+
+		return replay
+	}
+
+	return nil
+}
+
+// HubClient is a subscription to the hub
+type HubClient struct {
+	topics   map[string]struct{}
+	messages chan *Message
+}
+
+// Messages is closed when the client is unsubscribed, dropped for being slow or the hub is closed
+func (c *HubClient) Messages() <-chan *Message {
+	return c.messages
+}
+
+func (c *HubClient) subscribed(topic string) bool {
+	if len(c.topics) == 0 {
+		return true
+	}
+
+	_, ok := c.topics[topic]
+	return ok
+}
@@ -0,0 +1,99 @@
+package api_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/raafvargas/wrapit/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHubTopics(t *testing.T) {
+	hub := api.NewHub()
+
+	orders := hub.Subscribe("", "orders")
+	all := hub.Subscribe("")
+
+	assert.NoError(t, hub.Publish("orders", "created", map[string]string{"id": "1"}))
+	assert.NoError(t, hub.Publish("users", "created", map[string]string{"id": "2"}))
+
+	message := <-orders.Messages()
+	assert.True(t, strings.HasSuffix(message.ID, "-1"))
+	assert.Equal(t, "created", message.Event)
+	assert.JSONEq(t, `{"id":"1"}`, string(message.Data))
+	assert.Len(t, orders.Messages(), 0)
+	assert.Len(t, all.Messages(), 2)
+}
+
+func TestHubResume(t *testing.T) {
+	hub := api.NewHub()
+	all := hub.Subscribe("")
+
+	for i := 0; i < 3; i++ {
+		hub.Publish("orders", "", i)
+	}
+
+	client := hub.Subscribe((<-all.Messages()).ID, "orders")
+
+	assert.Equal(t, (<-all.Messages()).ID, (<-client.Messages()).ID)
+	assert.Equal(t, (<-all.Messages()).ID, (<-client.Messages()).ID)
+
+	unknown := hub.Subscribe("42", "orders")
+	assert.Len(t, unknown.Messages(), 0)
+}
+
+func TestHubHistorySize(t *testing.T) {
+	hub := api.NewHub(api.WithHubHistorySize(1))
+	all := hub.Subscribe("")
+
+	hub.Publish("orders", "", 1)
+	hub.Publish("orders", "", 2)
+
+	assert.Len(t, hub.Subscribe((<-all.Messages()).ID).Messages(), 0)
+}
+
+func TestHubEpoch(t *testing.T) {
+	previous := api.NewHub()
+	client := previous.Subscribe("")
+	previous.Publish("orders", "", 1)
+
+	time.Sleep(time.Millisecond)
+
+	hub := api.NewHub()
+	hub.Publish("orders", "", 1)
+	hub.Publish("orders", "", 2)
+
+	// an id from another process or a restart doesn't resume
+	assert.Len(t, hub.Subscribe((<-client.Messages()).ID).Messages(), 0)
+}
+
+func TestHubDropsSlowClients(t *testing.T) {
+	hub := api.NewHub(api.WithHubBufferSize(1))
+	client := hub.Subscribe("")
+
+	hub.Publish("orders", "", 1)
+	hub.Publish("orders", "", 2)
+
+	assert.Equal(t, 0, hub.Clients())
+
+	_, ok := <-client.Messages()
+	assert.True(t, ok)
+
+	_, ok = <-client.Messages()
+	assert.False(t, ok)
+}
+
+func TestHubClose(t *testing.T) {
+	hub := api.NewHub()
+	client := hub.Subscribe("")
+
+	hub.Close()
+	hub.Unsubscribe(client)
+
+	_, ok := <-client.Messages()
+	assert.False(t, ok)
+
+	_, ok = <-hub.Subscribe("").Messages()
+	assert.False(t, ok)
+}
@@ -0,0 +1,241 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raafvargas/wrapit/contract"
+	"github.com/raafvargas/wrapit/requestid"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// IdempotencyKeyHeader ...
+	IdempotencyKeyHeader = "Idempotency-Key"
+
+	// IdempotentReplayedHeader is set on replayed responses
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+
+	// DefaultIdempotencyTTL ...
+	DefaultIdempotencyTTL = 24 * time.Hour
+
+	// IdempotencyMaxBodyBytes bounds the body read to fingerprint the request. When 0 the
+	// body is only bounded by the MaxBodyBytes of the server.
+	IdempotencyMaxBodyBytes int64
+
+	// IdempotencyIgnoredHeaders are response headers set per request by the transport or
+	// other middlewares, they aren't stored nor replayed. Access-Control-* headers are
+	// always ignored.
+	IdempotencyIgnoredHeaders = []string{
+		"Connection", "Keep-Alive", "Proxy-Connection", "Te", "Trailer", "Transfer-Encoding",
+		"Upgrade", "Content-Length", "Date", "Vary", requestid.HeaderName,
+		"X-Content-Type-Options", "X-Frame-Options", "Content-Security-Policy",
+		"Referrer-Policy", "Strict-Transport-Security",
+	}
+)
+
+// Idempotency replays the stored response of POST requests carrying an already used
+// Idempotency-Key header. Requests still in progress get 409 and reused keys with a
+// different body get 422. Server errors release the key so clients can retry.
+func Idempotency(store contract.IdempotencyStore, ttl time.Duration) gin.HandlerFunc {
+	if ttl == 0 {
+		ttl = DefaultIdempotencyTTL
+	}
+
+	return func(ctx *gin.Context) {
+		header := ctx.GetHeader(IdempotencyKeyHeader)
+
+		if header == "" || ctx.Request.Method != http.MethodPost {
+			ctx.Next()
+			return
+		}
+
+		reader := ctx.Request.Body
+
+		if IdempotencyMaxBodyBytes > 0 {
+			reader = limitBody(reader, IdempotencyMaxBodyBytes)
+		}
+
+		body, err := ioutil.ReadAll(reader)
+
+		if err != nil {
+			BindingError(ctx, err)
+			return
+		}
+
+		ctx.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		sum := sha256.Sum256(body)
+		record := &contract.IdempotencyRecord{
+			Key:         ctx.Request.URL.Path + ":" + GetUserID(ctx) + ":" + header,
+			Fingerprint: hex.EncodeToString(sum[:]),
+			ExpiresAt:   time.Now().Add(ttl),
+		}
+
+		serveIdempotent(ctx, store, record)
+	}
+}
+
+// serveIdempotent reserves the record key and stores the response of the next handlers,
+// or replays the stored response when the key was already used
+func serveIdempotent(ctx *gin.Context, store contract.IdempotencyStore, record *contract.IdempotencyRecord) {
+	existing, reserved, err := store.Begin(ctx.Request.Context(), record)
+
+	if err != nil {
+		ResolveError(ctx, err)
+		return
+	}
+
+	if !reserved {
+		replay(ctx, record, existing)
+		return
+	}
+
+	writer := &recordingWriter{ResponseWriter: ctx.Writer, body: new(bytes.Buffer)}
+	ctx.Writer = writer
+
+	completed := false
+
+	defer func() {
+		if completed {
+			return
+		}
+
+		// the handler panicked or failed, allow the client to retry
+		if err := store.Release(context.Background(), record.Key); err != nil {
+			logrus.WithError(err).Error("couldn't release idempotency key")
+		}
+	}()
+
+	ctx.Next()
+
+	if writer.Status() >= http.StatusInternalServerError {
+		return
+	}
+
+	record.Completed = true
+	record.Status = writer.Status()
+	record.Headers = storedHeaders(writer.Header())
+	record.Body = writer.body.Bytes()
+
+	if err := store.Complete(ctx.Request.Context(), record); err != nil {
+		logrus.WithError(err).Error("couldn't store idempotent response")
+		return
+	}
+
+	completed = true
+}
+
+func replay(ctx *gin.Context, record, existing *contract.IdempotencyRecord) {
+	if existing.Fingerprint != record.Fingerprint {
+		ResolveError(ctx, contract.NewError(http.StatusUnprocessableEntity,
+			"idempotency key was already used with a different request"))
+		return
+	}
+
+	if !existing.Completed {
+		ResolveError(ctx, contract.NewError(http.StatusConflict,
+			"a request with the same idempotency key is in progress"))
+		return
+	}
+
+	header := ctx.Writer.Header()
+
+	for key, values := range existing.Headers {
+		header[http.CanonicalHeaderKey(key)] = append([]string(nil), values...)
+	}
+
+	ctx.Header(IdempotentReplayedHeader, "true")
+	ctx.Status(existing.Status)
+	ctx.Writer.Write(existing.Body)
+	ctx.Abort()
+}
+
+func storedHeaders(header http.Header) http.Header {
+	stored := header.Clone()
+
+	for key := range stored {
+		if strings.HasPrefix(key, "Access-Control-") {
+			delete(stored, key)
+		}
+	}
+
+	for _, key := range IdempotencyIgnoredHeaders {
+		stored.Del(key)
+	}
+
+	return stored
+}
+
+type recordingWriter struct {
+	gin.ResponseWriter
+	body *bytes.Buffer
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// MemoryIdempotencyStore keeps the records in memory, meant for tests and single replicas
+type MemoryIdempotencyStore struct {
+	mutex   *sync.Mutex
+	records map[string]*contract.IdempotencyRecord
+}
+
+// NewMemoryIdempotencyStore ...
+func NewMemoryIdempotencyStore() *MemoryIdempotencyStore {
+	return &MemoryIdempotencyStore{
+		mutex:   new(sync.Mutex),
+		records: make(map[string]*contract.IdempotencyRecord),
+	}
+}
+
+// Begin ...
+func (s *MemoryIdempotencyStore) Begin(_ context.Context, record *contract.IdempotencyRecord) (*contract.IdempotencyRecord, bool, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if existing, ok := s.records[record.Key]; ok && time.Now().Before(existing.ExpiresAt) {
+		return existing, false, nil
+	}
+
+	stored := *record
+	s.records[record.Key] = &stored
+
+	return nil, true, nil
+}
+
+// Complete ...
+func (s *MemoryIdempotencyStore) Complete(_ context.Context, record *contract.IdempotencyRecord) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stored := *record
+	s.records[record.Key] = &stored
+
+	return nil
+}
+
+// Release ...
+func (s *MemoryIdempotencyStore) Release(_ context.Context, key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	delete(s.records, key)
+
+	return nil
+}
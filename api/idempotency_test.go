@@ -0,0 +1,155 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raafvargas/wrapit/api"
+	"github.com/raafvargas/wrapit/requestid"
+	"github.com/stretchr/testify/assert"
+)
+
+func idempotentRequest(key, body string) *http.Request {
+	req := httptest.NewRequest(http.MethodPost, "/orders", strings.NewReader(body))
+	req.Header.Set(api.IdempotencyKeyHeader, key)
+	return req
+}
+
+func TestIdempotency(t *testing.T) {
+	calls := 0
+
+	engine := gin.New()
+	engine.Use(api.Idempotency(api.NewMemoryIdempotencyStore(), time.Minute))
+	engine.POST("orders", func(ctx *gin.Context) {
+		calls++
+		ctx.Header("Location", "/orders/1")
+		ctx.JSON(http.StatusCreated, gin.H{"id": calls})
+	})
+
+	first := httptest.NewRecorder()
+	engine.ServeHTTP(first, idempotentRequest("key", `{"a":1}`))
+
+	replayed := httptest.NewRecorder()
+	engine.ServeHTTP(replayed, idempotentRequest("key", `{"a":1}`))
+
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, http.StatusCreated, replayed.Code)
+	assert.Equal(t, first.Body.String(), replayed.Body.String())
+	assert.Equal(t, "/orders/1", replayed.Header().Get("Location"))
+	assert.Equal(t, "true", replayed.Header().Get(api.IdempotentReplayedHeader))
+
+	mismatch := httptest.NewRecorder()
+	engine.ServeHTTP(mismatch, idempotentRequest("key", `{"a":2}`))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, mismatch.Code)
+	assert.Equal(t, 1, calls)
+}
+
+func TestIdempotencyInProgress(t *testing.T) {
+	started := make(chan bool)
+	release := make(chan bool)
+
+	engine := gin.New()
+	engine.Use(api.Idempotency(api.NewMemoryIdempotencyStore(), 0))
+	engine.POST("orders", func(ctx *gin.Context) {
+		started <- true
+		<-release
+		ctx.Status(http.StatusCreated)
+	})
+
+	done := make(chan bool)
+
+	go func() {
+		engine.ServeHTTP(httptest.NewRecorder(), idempotentRequest("key", `{}`))
+		done <- true
+	}()
+
+	<-started
+
+	res := httptest.NewRecorder()
+	engine.ServeHTTP(res, idempotentRequest("key", `{}`))
+	assert.Equal(t, http.StatusConflict, res.Code)
+
+	release <- true
+	<-done
+}
+
+func TestIdempotencyServerErrorReleasesKey(t *testing.T) {
+	calls := 0
+
+	engine := gin.New()
+	engine.Use(api.Idempotency(api.NewMemoryIdempotencyStore(), 0))
+	engine.POST("orders", func(ctx *gin.Context) {
+		calls++
+		ctx.Status(http.StatusServiceUnavailable)
+	})
+
+	engine.ServeHTTP(httptest.NewRecorder(), idempotentRequest("key", `{}`))
+	engine.ServeHTTP(httptest.NewRecorder(), idempotentRequest("key", `{}`))
+
+	assert.Equal(t, 2, calls)
+}
+
+func TestIdempotencyReplayHeaders(t *testing.T) {
+	engine := gin.New()
+	engine.Use(api.RequestID(), api.Idempotency(api.NewMemoryIdempotencyStore(), 0))
+	engine.POST("orders", func(ctx *gin.Context) {
+		ctx.Header("X-Order", "1")
+		ctx.Status(http.StatusCreated)
+	})
+
+	first := httptest.NewRecorder()
+	engine.ServeHTTP(first, idempotentRequest("key", `{}`))
+
+	replayed := httptest.NewRecorder()
+	engine.ServeHTTP(replayed, idempotentRequest("key", `{}`))
+
+	assert.Equal(t, []string{"1"}, replayed.Header().Values("X-Order"))
+	assert.Len(t, replayed.Header().Values(requestid.HeaderName), 1)
+	assert.NotEqual(t, first.Header().Get(requestid.HeaderName), replayed.Header().Get(requestid.HeaderName))
+}
+
+func TestIdempotencyBodyTooLarge(t *testing.T) {
+	limit := api.IdempotencyMaxBodyBytes
+	api.IdempotencyMaxBodyBytes = 4
+	defer func() { api.IdempotencyMaxBodyBytes = limit }()
+
+	engine := gin.New()
+	engine.Use(api.Idempotency(api.NewMemoryIdempotencyStore(), 0))
+	engine.POST("orders", func(ctx *gin.Context) {
+		ctx.Status(http.StatusCreated)
+	})
+
+	res := httptest.NewRecorder()
+	engine.ServeHTTP(res, idempotentRequest("key", `{"a":1}`))
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, res.Code)
+}
+
+func TestIdempotencyServerBodyLimit(t *testing.T) {
+	engine := gin.New()
+	engine.Use(api.Idempotency(api.NewMemoryIdempotencyStore(), 0))
+	engine.POST("orders", func(ctx *gin.Context) {
+		ctx.Status(http.StatusCreated)
+	})
+
+	res := httptest.NewRecorder()
+	engine.ServeHTTP(res, idempotentRequest("key", `"`+strings.Repeat("a", 2<<20)+`"`))
+
+	assert.Equal(t, http.StatusCreated, res.Code)
+
+	engine = gin.New()
+	engine.Use(api.MaxBodySize(4), api.Idempotency(api.NewMemoryIdempotencyStore(), 0))
+	engine.POST("orders", func(ctx *gin.Context) {
+		ctx.Status(http.StatusCreated)
+	})
+
+	res = httptest.NewRecorder()
+	engine.ServeHTTP(res, idempotentRequest("key", `{"a":1}`))
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, res.Code)
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raafvargas/wrapit/auth"
+	"github.com/raafvargas/wrapit/requestid"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/api/trace"
+)
+
+var (
+	// DefaultLogSkipPaths are not logged, along with the server metrics path
+	DefaultLogSkipPaths = []string{"/healthz", "/livez", "/readyz"}
+
+	// UserIDClaim ...
+	UserIDClaim = "sub"
+)
+
+// Logger writes one structured access log entry per request
+func Logger(logger *logrus.Logger, skipPaths ...string) gin.HandlerFunc {
+	skip := make(map[string]bool, len(skipPaths))
+
+	for _, path := range skipPaths {
+		skip["/"+strings.TrimPrefix(path, "/")] = true
+	}
+
+	return func(ctx *gin.Context) {
+		start := time.Now()
+
+		ctx.Next()
+
+		if skip[ctx.Request.URL.Path] {
+			return
+		}
+
+		status := ctx.Writer.Status()
+
+		entry := logger.WithFields(logrus.Fields{
+			"method":     ctx.Request.Method,
+			"route":      ctx.FullPath(),
+			"path":       ctx.Request.URL.Path,
+			"status":     status,
+			"latency_ms": float64(time.Since(start).Microseconds()) / 1000,
+			"bytes":      ctx.Writer.Size(),
+			"client_ip":  ctx.ClientIP(),
+		})
+
+		if userID := GetUserID(ctx); userID != "" {
+			entry = entry.WithField("user_id", userID)
+		}
+
+		if requestID := GetRequestID(ctx); requestID != "" {
+			entry = entry.WithField(requestid.LogField, requestID)
+		}
+
+		spanContext := trace.SpanFromContext(ctx.Request.Context()).SpanContext()
+
+		if spanContext.IsValid() {
+			entry = entry.WithField("trace_id", spanContext.TraceID.String()).
+				WithField("span_id", spanContext.SpanID.String())
+		}
+
+		if len(ctx.Errors) > 0 {
+			entry = entry.WithField("errors", ctx.Errors.String())
+		}
+
+		switch {
+		case status >= 500:
+			entry.Error("request completed")
+		case status >= 400:
+			entry.Warn("request completed")
+		default:
+			entry.Info("request completed")
+		}
+	}
+}
+
+// GetUserID returns the subject of the auth principal, falling back to the
+// UserIDClaim key for handlers setting claims directly
+func GetUserID(ctx *gin.Context) string {
+	if principal, ok := auth.GetPrincipal(ctx); ok {
+		return principal.Subject
+	}
+
+	return ctx.GetString(UserIDClaim)
+}
@@ -0,0 +1,42 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raafvargas/wrapit/api"
+	"github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogger(t *testing.T) {
+	logger, hook := test.NewNullLogger()
+
+	engine := gin.New()
+	engine.Use(api.Logger(logger, "healthz"))
+	engine.GET("healthz", func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+	engine.GET("users/:id", func(ctx *gin.Context) {
+		ctx.Set(api.UserIDClaim, "user")
+		ctx.Status(http.StatusNotFound)
+	})
+
+	res := httptest.NewRecorder()
+	engine.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Empty(t, hook.AllEntries())
+
+	res = httptest.NewRecorder()
+	engine.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/users/10", nil))
+
+	entry := hook.LastEntry()
+	assert.NotNil(t, entry)
+	assert.Equal(t, logrus.WarnLevel, entry.Level)
+	assert.Equal(t, "/users/:id", entry.Data["route"])
+	assert.Equal(t, http.StatusNotFound, entry.Data["status"])
+	assert.Equal(t, "user", entry.Data["user_id"])
+	assert.IsType(t, float64(0), entry.Data["latency_ms"])
+}
@@ -0,0 +1,114 @@
+package api
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	// DefaultMetricsPath ...
+	DefaultMetricsPath = "metrics"
+
+	// DefaultMetricsBuckets ...
+	DefaultMetricsBuckets = prometheus.DefBuckets
+
+	// UnmatchedRoute labels requests that didn't match any route
+	UnmatchedRoute = "unmatched"
+
+	// ErrMetricsBuckets ...
+	ErrMetricsBuckets = errors.New("http metrics already registered with different buckets")
+)
+
+// HTTPMetrics ...
+type HTTPMetrics struct {
+	requests *prometheus.CounterVec
+	duration *durationHistogram
+	inFlight prometheus.Gauge
+}
+
+// durationHistogram keeps the buckets of the registered latency histogram to detect conflicts
+type durationHistogram struct {
+	*prometheus.HistogramVec
+	buckets []float64
+}
+
+// NewHTTPMetrics registers the request counter, latency histogram and in-flight gauge.
+// Collectors already registered in registerer by another server are reused when their buckets
+// match, otherwise it returns ErrMetricsBuckets. Servers with different buckets need their own registerer.
+func NewHTTPMetrics(registerer prometheus.Registerer, buckets []float64) (*HTTPMetrics, error) {
+	if len(buckets) == 0 {
+		buckets = DefaultMetricsBuckets
+	}
+
+	m := &HTTPMetrics{}
+
+	duration, ok := registerCollector(registerer, &durationHistogram{
+		HistogramVec: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_duration_seconds",
+			Help:    "HTTP request latency in seconds.",
+			Buckets: buckets,
+		}, []string{"method", "route", "status"}),
+		buckets: buckets,
+	}).(*durationHistogram)
+
+	if !ok || !reflect.DeepEqual(duration.buckets, buckets) {
+		return nil, ErrMetricsBuckets
+	}
+
+	m.duration = duration
+
+	m.requests = registerCollector(registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "Total number of HTTP requests.",
+	}, []string{"method", "route", "status"})).(*prometheus.CounterVec)
+
+	m.inFlight = registerCollector(registerer, prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "http_requests_in_flight",
+		Help: "Number of HTTP requests being served.",
+	})).(prometheus.Gauge)
+
+	return m, nil
+}
+
+// HTTP ...
+func (m *HTTPMetrics) HTTP() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		start := time.Now()
+
+		m.inFlight.Inc()
+		defer m.inFlight.Dec()
+
+		ctx.Next()
+
+		route := ctx.FullPath()
+
+		if route == "" {
+			route = UnmatchedRoute
+		}
+
+		status := strconv.Itoa(ctx.Writer.Status()/100) + "xx"
+
+		m.requests.WithLabelValues(ctx.Request.Method, route, status).Inc()
+		m.duration.WithLabelValues(ctx.Request.Method, route, status).
+			Observe(time.Since(start).Seconds())
+	}
+}
+
+func registerCollector(registerer prometheus.Registerer, collector prometheus.Collector) prometheus.Collector {
+	if err := registerer.Register(collector); err != nil {
+		var registered prometheus.AlreadyRegisteredError
+
+		if errors.As(err, &registered) {
+			return registered.ExistingCollector
+		}
+
+		panic(err)
+	}
+
+	return collector
+}
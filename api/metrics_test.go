@@ -0,0 +1,70 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/raafvargas/wrapit/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPMetrics(t *testing.T) {
+	registry := prometheus.NewRegistry()
+	metrics, err := api.NewHTTPMetrics(registry, nil)
+	assert.NoError(t, err)
+
+	engine := gin.New()
+	engine.Use(metrics.HTTP())
+	engine.GET("users/:id", func(ctx *gin.Context) {
+		ctx.Status(http.StatusNoContent)
+	})
+
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/2", nil))
+	engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/notfound", nil))
+
+	count, err := testutil.GatherAndCount(registry, "http_requests_total")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, count)
+
+	families, err := registry.Gather()
+	assert.NoError(t, err)
+
+	for _, family := range families {
+		if family.GetName() != "http_requests_total" {
+			continue
+		}
+
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+
+			if labels["route"] == "/users/:id" {
+				assert.Equal(t, "2xx", labels["status"])
+				assert.Equal(t, float64(2), metric.GetCounter().GetValue())
+			} else {
+				assert.Equal(t, api.UnmatchedRoute, labels["route"])
+				assert.Equal(t, "4xx", labels["status"])
+			}
+		}
+	}
+}
+
+func TestHTTPMetricsAlreadyRegistered(t *testing.T) {
+	registry := prometheus.NewRegistry()
+
+	_, err := api.NewHTTPMetrics(registry, nil)
+	assert.NoError(t, err)
+
+	_, err = api.NewHTTPMetrics(registry, api.DefaultMetricsBuckets)
+	assert.NoError(t, err)
+
+	_, err = api.NewHTTPMetrics(registry, []float64{1})
+	assert.Equal(t, api.ErrMetricsBuckets, err)
+}
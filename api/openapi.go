@@ -0,0 +1,538 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raafvargas/wrapit/contract"
+	swaggerFiles "github.com/swaggo/files"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	// OpenAPIVersion ...
+	OpenAPIVersion = "3.0.3"
+
+	// SwaggerUIURL is the base URL of the Swagger UI assets. When empty the docs page
+	// uses the assets embedded in the binary, served under the docs path.
+	SwaggerUIURL = ""
+
+	swaggerUIAssets = map[string]struct {
+		contentType string
+		data        []byte
+	}{
+		"swagger-ui.css":       {"text/css; charset=utf-8", swaggerFiles.FileSwaggerUICSS},
+		"swagger-ui-bundle.js": {"application/javascript", swaggerFiles.FileSwaggerUIBundleJs},
+		"init.js":              {"application/javascript", []byte(swaggerUIInit)},
+	}
+
+	// BearerSecurityScheme ...
+	BearerSecurityScheme = "bearer"
+)
+
+// DescribedController is implemented by controllers that document their routes
+type DescribedController interface {
+	Controller
+	Describe() []RouteDescription
+}
+
+// RouteDescription documents a single route.
+// Params and Query are structs using uri and form tags, Request and Responses
+// values are samples of the body types.
+type RouteDescription struct {
+	Method      string
+	Path        string
+	Summary     string
+	Description string
+	Tags        []string
+	Params      interface{}
+	Query       interface{}
+	Request     interface{}
+	Responses   map[int]interface{}
+	Scopes      []string
+	Errors      []int
+	Deprecated  bool
+}
+
+// OpenAPIInfo ...
+type OpenAPIInfo struct {
+	Title       string `json:"title" yaml:"title"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Version     string `json:"version" yaml:"version"`
+}
+
+// OpenAPI ...
+type OpenAPI struct {
+	OpenAPI    string                           `json:"openapi" yaml:"openapi"`
+	Info       OpenAPIInfo                      `json:"info" yaml:"info"`
+	Paths      map[string]map[string]*Operation `json:"paths" yaml:"paths"`
+	Components Components                       `json:"components" yaml:"components"`
+
+	schemaTypes map[string]reflect.Type
+}
+
+// Components ...
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas,omitempty" yaml:"schemas,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty" yaml:"securitySchemes,omitempty"`
+}
+
+// SecurityScheme ...
+type SecurityScheme struct {
+	Type         string `json:"type" yaml:"type"`
+	Scheme       string `json:"scheme,omitempty" yaml:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty" yaml:"bearerFormat,omitempty"`
+}
+
+// Operation ...
+type Operation struct {
+	Summary     string                `json:"summary,omitempty" yaml:"summary,omitempty"`
+	Description string                `json:"description,omitempty" yaml:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Parameters  []*Parameter          `json:"parameters,omitempty" yaml:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty" yaml:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses" yaml:"responses"`
+	Security    []map[string][]string `json:"security,omitempty" yaml:"security,omitempty"`
+	Scopes      []string              `json:"x-scopes,omitempty" yaml:"x-scopes,omitempty"`
+	Deprecated  bool                  `json:"deprecated,omitempty" yaml:"deprecated,omitempty"`
+}
+
+// Parameter ...
+type Parameter struct {
+	Name     string  `json:"name" yaml:"name"`
+	In       string  `json:"in" yaml:"in"`
+	Required bool    `json:"required,omitempty" yaml:"required,omitempty"`
+	Schema   *Schema `json:"schema" yaml:"schema"`
+}
+
+// RequestBody ...
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty" yaml:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content" yaml:"content"`
+}
+
+// Response ...
+type Response struct {
+	Description string                `json:"description" yaml:"description"`
+	Content     map[string]*MediaType `json:"content,omitempty" yaml:"content,omitempty"`
+}
+
+// MediaType ...
+type MediaType struct {
+	Schema *Schema `json:"schema" yaml:"schema"`
+}
+
+// Schema ...
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty" yaml:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty" yaml:"type,omitempty"`
+	Format               string             `json:"format,omitempty" yaml:"format,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty" yaml:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty" yaml:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty" yaml:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty" yaml:"additionalProperties,omitempty"`
+	Enum                 []string           `json:"enum,omitempty" yaml:"enum,omitempty"`
+}
+
+// NewOpenAPI generates the document for the given route descriptions
+func NewOpenAPI(info OpenAPIInfo, routes []RouteDescription) *OpenAPI {
+	doc := &OpenAPI{
+		OpenAPI: OpenAPIVersion,
+		Info:    info,
+		Paths:   make(map[string]map[string]*Operation),
+		Components: Components{
+			Schemas: make(map[string]*Schema),
+		},
+		schemaTypes: make(map[string]reflect.Type),
+	}
+
+	for _, route := range routes {
+		doc.AddRoute(route)
+	}
+
+	return doc
+}
+
+// AddRoute ...
+func (doc *OpenAPI) AddRoute(route RouteDescription) {
+	path, pathParams := openAPIPath(route.Path)
+
+	operation := &Operation{
+		Summary:     route.Summary,
+		Description: route.Description,
+		Tags:        route.Tags,
+		Deprecated:  route.Deprecated,
+		Responses:   make(map[string]*Response),
+	}
+
+	documented := map[string]bool{}
+
+	for _, param := range doc.parameters(route.Params, "path", "uri") {
+		param.Required = true
+		documented[param.Name] = true
+		operation.Parameters = append(operation.Parameters, param)
+	}
+
+	for _, name := range pathParams {
+		if documented[name] {
+			continue
+		}
+
+		operation.Parameters = append(operation.Parameters, &Parameter{
+			Name:     name,
+			In:       "path",
+			Required: true,
+			Schema:   &Schema{Type: "string"},
+		})
+	}
+
+	operation.Parameters = append(operation.Parameters, doc.parameters(route.Query, "query", "form")...)
+
+	if route.Request != nil {
+		operation.RequestBody = &RequestBody{
+			Required: true,
+			Content: map[string]*MediaType{
+				gin.MIMEJSON: {Schema: doc.schema(reflect.TypeOf(route.Request))},
+			},
+		}
+	}
+
+	// sorted so colliding schema names are assigned the same way on every run
+	statuses := make([]int, 0, len(route.Responses))
+
+	for status := range route.Responses {
+		statuses = append(statuses, status)
+	}
+
+	sort.Ints(statuses)
+
+	for _, status := range statuses {
+		body := route.Responses[status]
+		response := &Response{Description: http.StatusText(status)}
+
+		if body != nil {
+			response.Content = map[string]*MediaType{
+				gin.MIMEJSON: {Schema: doc.schema(reflect.TypeOf(body))},
+			}
+		}
+
+		operation.Responses[fmt.Sprint(status)] = response
+	}
+
+	for _, status := range route.Errors {
+		operation.Responses[fmt.Sprint(status)] = &Response{
+			Description: http.StatusText(status),
+			Content: map[string]*MediaType{
+				gin.MIMEJSON:                {Schema: doc.schema(reflect.TypeOf(contract.Error{}))},
+				contract.ProblemContentType: {Schema: &Schema{Type: "object"}},
+			},
+		}
+	}
+
+	if len(operation.Responses) == 0 {
+		operation.Responses["default"] = &Response{Description: "response"}
+	}
+
+	if route.Scopes != nil {
+		if doc.Components.SecuritySchemes == nil {
+			doc.Components.SecuritySchemes = map[string]*SecurityScheme{
+				BearerSecurityScheme: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			}
+		}
+
+		// http schemes can't list scopes, they're documented in the x-scopes extension
+		operation.Security = []map[string][]string{
+			{BearerSecurityScheme: {}},
+		}
+		operation.Scopes = route.Scopes
+	}
+
+	if doc.Paths[path] == nil {
+		doc.Paths[path] = make(map[string]*Operation)
+	}
+
+	doc.Paths[path][strings.ToLower(route.Method)] = operation
+}
+
+// YAML ...
+func (doc *OpenAPI) YAML() ([]byte, error) {
+	return yaml.Marshal(doc)
+}
+
+// OpenAPIHandler serves the document as JSON or YAML, and the Swagger UI page.
+// Routed with an asset param, e.g. "docs/ui/*asset", it serves the embedded UI assets.
+func OpenAPIHandler(doc *OpenAPI) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if name := strings.TrimPrefix(ctx.Param("asset"), "/"); name != "" {
+			asset, ok := swaggerUIAssets[name]
+
+			if !ok {
+				ctx.AbortWithStatus(http.StatusNotFound)
+				return
+			}
+
+			ctx.Data(http.StatusOK, asset.contentType, asset.data)
+			return
+		}
+
+		switch {
+		case strings.HasSuffix(ctx.Request.URL.Path, ".json"):
+			ctx.JSON(http.StatusOK, doc)
+		case strings.HasSuffix(ctx.Request.URL.Path, ".yaml"):
+			data, err := doc.YAML()
+
+			if err != nil {
+				ResolveError(ctx, err)
+				return
+			}
+
+			ctx.Data(http.StatusOK, "application/yaml", data)
+		default:
+			base := strings.TrimSuffix(ctx.Request.URL.Path, "/")
+			ctx.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerUI(base)))
+		}
+	}
+}
+
+func (doc *OpenAPI) parameters(sample interface{}, in, tag string) []*Parameter {
+	if sample == nil {
+		return nil
+	}
+
+	t := reflect.TypeOf(sample)
+
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	params := []*Parameter{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name := strings.Split(field.Tag.Get(tag), ",")[0]
+
+		if field.PkgPath != "" || name == "-" {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		params = append(params, &Parameter{
+			Name:     name,
+			In:       in,
+			Required: requiredField(field),
+			Schema:   doc.schema(field.Type),
+		})
+	}
+
+	return params
+}
+
+func (doc *OpenAPI) schema(t reflect.Type) *Schema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == reflect.TypeOf(time.Time{}) {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+
+		return &Schema{Type: "array", Items: doc.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: doc.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return doc.structSchema(t)
+		}
+
+		name := doc.schemaName(t)
+
+		if _, ok := doc.Components.Schemas[name]; !ok {
+			// reserve the name before walking the fields to support recursive types
+			doc.schemaTypes[name] = t
+			doc.Components.Schemas[name] = &Schema{}
+			*doc.Components.Schemas[name] = *doc.structSchema(t)
+		}
+
+		return &Schema{Ref: "#/components/schemas/" + name}
+	default:
+		return &Schema{}
+	}
+}
+
+// schemaName is the type name, qualified by its package path when another
+// package already registered a type with the same name
+func (doc *OpenAPI) schemaName(t reflect.Type) string {
+	name := t.Name()
+
+	if registered, ok := doc.schemaTypes[name]; !ok || registered == t {
+		return name
+	}
+
+	return strings.NewReplacer("/", ".", "~", ".").Replace(t.PkgPath()) + "." + name
+}
+
+func (doc *OpenAPI) structSchema(t reflect.Type) *Schema {
+	schema := &Schema{
+		Type:       "object",
+		Properties: make(map[string]*Schema),
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+
+		// unexported embedded structs still promote their exported fields
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+
+		if name == "-" {
+			continue
+		}
+
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+
+			for embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+
+			if embedded.Kind() == reflect.Struct {
+				inner := doc.structSchema(embedded)
+
+				for k, v := range inner.Properties {
+					schema.Properties[k] = v
+				}
+
+				schema.Required = append(schema.Required, inner.Required...)
+				continue
+			}
+		}
+
+		if field.PkgPath != "" {
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		property := doc.schema(field.Type)
+
+		if enum := tagParam(field, "oneof"); enum != "" {
+			property.Enum = strings.Fields(enum)
+		}
+
+		schema.Properties[name] = property
+
+		if requiredField(field) {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+
+	sort.Strings(schema.Required)
+
+	return schema
+}
+
+func requiredField(field reflect.StructField) bool {
+	for _, tag := range []string{"binding", "validate"} {
+		for _, rule := range strings.Split(field.Tag.Get(tag), ",") {
+			if rule == "required" {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func tagParam(field reflect.StructField, name string) string {
+	for _, tag := range []string{"binding", "validate"} {
+		for _, rule := range strings.Split(field.Tag.Get(tag), ",") {
+			if strings.HasPrefix(rule, name+"=") {
+				return strings.TrimPrefix(rule, name+"=")
+			}
+		}
+	}
+
+	return ""
+}
+
+func openAPIPath(path string) (string, []string) {
+	segments := strings.Split(path, "/")
+	params := []string{}
+
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") || strings.HasPrefix(segment, "*") {
+			name := segment[1:]
+			params = append(params, name)
+			segments[i] = "{" + name + "}"
+		}
+	}
+
+	return "/" + strings.TrimPrefix(strings.Join(segments, "/"), "/"), params
+}
+
+// swaggerUI renders the docs page without inline scripts to work under strict CSPs
+func swaggerUI(base string) string {
+	assets := SwaggerUIURL
+
+	if assets == "" {
+		assets = base + "/ui"
+	}
+
+	assets = strings.TrimSuffix(assets, "/")
+
+	return `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>API documentation</title>
+<link rel="stylesheet" href="` + assets + `/swagger-ui.css">
+</head>
+<body>
+<div id="swagger-ui" data-url="` + base + `/openapi.json"></div>
+<script src="` + assets + `/swagger-ui-bundle.js"></script>
+<script src="` + base + `/ui/init.js"></script>
+</body>
+</html>`
+}
+
+const swaggerUIInit = `window.ui = SwaggerUIBundle({
+  url: document.getElementById("swagger-ui").getAttribute("data-url"),
+  dom_id: "#swagger-ui"
+});
+`
@@ -0,0 +1,200 @@
+package api_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raafvargas/wrapit/api"
+	"github.com/raafvargas/wrapit/contract"
+	"github.com/stretchr/testify/assert"
+)
+
+type userParams struct {
+	ID string `uri:"id" binding:"required,uuid"`
+}
+
+type userQuery struct {
+	Fields string `form:"fields"`
+}
+
+type userRequest struct {
+	Name    string    `json:"name" binding:"required"`
+	Role    string    `json:"role" binding:"oneof=admin user"`
+	Tags    []string  `json:"tags"`
+	Created time.Time `json:"created_at"`
+	Ignored string    `json:"-"`
+}
+
+type userResponse struct {
+	userRequest
+	ID string `json:"id"`
+}
+
+type describedController struct{}
+
+func (*describedController) RegisterRoutes(router *gin.RouterGroup) {
+	router.PUT("users/:id", func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+}
+
+func (*describedController) Describe() []api.RouteDescription {
+	return []api.RouteDescription{
+		{
+			Method:    http.MethodPut,
+			Path:      "users/:id",
+			Summary:   "update user",
+			Params:    userParams{},
+			Query:     userQuery{},
+			Request:   userRequest{},
+			Responses: map[int]interface{}{http.StatusOK: userResponse{}},
+			Scopes:    []string{"users:write"},
+			Errors:    []int{http.StatusNotFound},
+		},
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	doc := api.NewOpenAPI(api.OpenAPIInfo{Title: "test", Version: "1"}, new(describedController).Describe())
+
+	operation := doc.Paths["/users/{id}"]["put"]
+	assert.NotNil(t, operation)
+	assert.Equal(t, "update user", operation.Summary)
+	assert.Len(t, operation.Parameters, 2)
+	assert.Equal(t, "path", operation.Parameters[0].In)
+	assert.Equal(t, "id", operation.Parameters[0].Name)
+	assert.Equal(t, "query", operation.Parameters[1].In)
+	assert.Equal(t, "#/components/schemas/userRequest", operation.RequestBody.Content["application/json"].Schema.Ref)
+	assert.Contains(t, operation.Responses, "404")
+	assert.Equal(t, []string{}, operation.Security[0][api.BearerSecurityScheme])
+	assert.Equal(t, []string{"users:write"}, operation.Scopes)
+
+	request := doc.Components.Schemas["userRequest"]
+	assert.Equal(t, []string{"name"}, request.Required)
+	assert.Equal(t, []string{"admin", "user"}, request.Properties["role"].Enum)
+	assert.Equal(t, "date-time", request.Properties["created_at"].Format)
+	assert.Equal(t, "array", request.Properties["tags"].Type)
+	assert.NotContains(t, request.Properties, "Ignored")
+
+	response := doc.Components.Schemas["userResponse"]
+	assert.Contains(t, response.Properties, "name")
+	assert.Contains(t, response.Properties, "id")
+
+	_, err := doc.YAML()
+	assert.NoError(t, err)
+}
+
+// Error collides with contract.Error used by the error responses
+type Error struct {
+	Reason string `json:"reason"`
+}
+
+func TestOpenAPISchemaNameCollision(t *testing.T) {
+	doc := api.NewOpenAPI(api.OpenAPIInfo{Title: "test", Version: "1"}, []api.RouteDescription{
+		{
+			Method:    http.MethodGet,
+			Path:      "errors",
+			Responses: map[int]interface{}{http.StatusOK: Error{}},
+			Errors:    []int{http.StatusNotFound},
+		},
+	})
+
+	operation := doc.Paths["/errors"]["get"]
+	ok := operation.Responses["200"].Content["application/json"].Schema.Ref
+	notFound := operation.Responses["404"].Content["application/json"].Schema.Ref
+
+	assert.NotEqual(t, ok, notFound)
+	assert.Equal(t, "#/components/schemas/github.com.raafvargas.wrapit.contract.Error", notFound)
+	assert.Contains(t, doc.Components.Schemas["Error"].Properties, "reason")
+}
+
+func TestOpenAPISchemaNameCollisionOrder(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		doc := api.NewOpenAPI(api.OpenAPIInfo{Title: "test", Version: "1"}, []api.RouteDescription{
+			{
+				Method: http.MethodGet,
+				Path:   "errors",
+				Responses: map[int]interface{}{
+					http.StatusOK:       Error{},
+					http.StatusAccepted: contract.Error{},
+				},
+			},
+		})
+
+		operation := doc.Paths["/errors"]["get"]
+
+		assert.Equal(t, "#/components/schemas/Error", operation.Responses["200"].Content["application/json"].Schema.Ref)
+		assert.Equal(t, "#/components/schemas/github.com.raafvargas.wrapit.contract.Error",
+			operation.Responses["202"].Content["application/json"].Schema.Ref)
+	}
+}
+
+func TestServerOpenAPI(t *testing.T) {
+	server := api.New(
+		api.WithController(new(describedController)),
+		api.WithOpenAPI("docs", api.OpenAPIInfo{Title: "test", Version: "1"}),
+	)
+
+	ts := httptest.NewServer(server.Engine)
+	defer ts.Close()
+
+	for _, path := range []string{"docs", "docs/openapi.json", "docs/openapi.yaml", "docs/ui/swagger-ui-bundle.js", "docs/ui/init.js"} {
+		res, err := http.Get(fmt.Sprintf("%s/%s", ts.URL, path))
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, http.StatusOK, res.StatusCode)
+		res.Body.Close()
+	}
+
+	res, err := http.Get(fmt.Sprintf("%s/docs/openapi.json", ts.URL))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer res.Body.Close()
+
+	body, _ := ioutil.ReadAll(res.Body)
+	doc := new(api.OpenAPI)
+
+	assert.NoError(t, json.Unmarshal(body, doc))
+	assert.Equal(t, api.OpenAPIVersion, doc.OpenAPI)
+	assert.Contains(t, doc.Paths, "/users/{id}")
+
+	res, err = http.Get(fmt.Sprintf("%s/docs/ui/unknown.js", ts.URL))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res.Body.Close()
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func TestOpenAPISwaggerUIURL(t *testing.T) {
+	engine := gin.New()
+	engine.GET("docs", api.OpenAPIHandler(api.NewOpenAPI(api.OpenAPIInfo{}, nil)))
+
+	res := httptest.NewRecorder()
+	engine.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/docs", nil))
+
+	assert.Contains(t, res.Body.String(), `src="/docs/ui/swagger-ui-bundle.js"`)
+	assert.NotContains(t, res.Body.String(), "unpkg.com")
+
+	api.SwaggerUIURL = "https://cdn.example.com/swagger-ui/"
+	defer func() { api.SwaggerUIURL = "" }()
+
+	res = httptest.NewRecorder()
+	engine.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/docs", nil))
+
+	assert.Contains(t, res.Body.String(), `src="https://cdn.example.com/swagger-ui/swagger-ui-bundle.js"`)
+}
@@ -1,7 +1,12 @@
 package api
 
 import (
+	"os"
+
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/sirupsen/logrus"
 )
 
 // Option wrapps all server configurations
@@ -14,6 +19,14 @@ func WithHost(host string) Option {
 	}
 }
 
+// WithConfig ...
+func WithConfig(config *Config) Option {
+	return func(server *Server) {
+		server.Config = config
+		server.Host = config.Host
+	}
+}
+
 // WithController ...
 func WithController(controller Controller) Option {
 	return func(server *Server) {
@@ -35,6 +48,20 @@ func WithHealthz(healthz gin.HandlerFunc) Option {
 	}
 }
 
+// WithLiveness ...
+func WithLiveness(liveness gin.HandlerFunc) Option {
+	return func(server *Server) {
+		server.Liveness = liveness
+	}
+}
+
+// WithReadiness sets the readiness check used while the server isn't draining
+func WithReadiness(readiness gin.HandlerFunc) Option {
+	return func(server *Server) {
+		server.Readiness = readiness
+	}
+}
+
 // WithHandler ...
 func WithHandler(handler gin.HandlerFunc) Option {
 	return func(server *Server) {
@@ -48,3 +75,96 @@ func WithNoRoute(handler gin.HandlerFunc) Option {
 		server.NoRoute = append(server.NoRoute, handler)
 	}
 }
+
+// WithLogger ...
+func WithLogger(logger *logrus.Logger) Option {
+	return func(server *Server) {
+		server.Logger = logger
+	}
+}
+
+// WithLogSkipPaths ...
+func WithLogSkipPaths(paths ...string) Option {
+	return func(server *Server) {
+		server.LogSkipPaths = paths
+	}
+}
+
+// WithMetricsPath sets the metrics endpoint path. An empty path disables it.
+func WithMetricsPath(path string) Option {
+	return func(server *Server) {
+		server.MetricsPath = path
+	}
+}
+
+// WithMetricsBuckets ...
+func WithMetricsBuckets(buckets ...float64) Option {
+	return func(server *Server) {
+		server.MetricsBuckets = buckets
+	}
+}
+
+// WithMetricsRegisterer registers the HTTP metrics in registerer instead of the default one,
+// serving them on the metrics endpoint when it's also a prometheus.Gatherer
+func WithMetricsRegisterer(registerer prometheus.Registerer) Option {
+	return func(server *Server) {
+		server.MetricsRegisterer = registerer
+
+		if gatherer, ok := registerer.(prometheus.Gatherer); ok {
+			server.Metrics = promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{})
+		}
+	}
+}
+
+// WithProblemDetails renders errors as application/problem+json
+// unless the client only accepts application/json
+func WithProblemDetails() Option {
+	return func(server *Server) {
+		server.ProblemDetails = true
+	}
+}
+
+// WithOpenAPI serves the OpenAPI document generated from the described controllers
+// and the Swagger UI under path
+func WithOpenAPI(path string, info OpenAPIInfo) Option {
+	return func(server *Server) {
+		server.OpenAPIPath = path
+		server.OpenAPIInfo = info
+	}
+}
+
+// WithHub closes the hub on shutdown, disconnecting its SSE and WebSocket clients
+func WithHub(hub *Hub) Option {
+	return func(server *Server) {
+		server.Hubs = append(server.Hubs, hub)
+	}
+}
+
+// WithSignals sets the signals triggering the shutdown. No signals leaves it to the Run context.
+func WithSignals(signals ...os.Signal) Option {
+	return func(server *Server) {
+		server.Signals = signals
+	}
+}
+
+// WithSettings sets the effective configuration exposed, redacted, by the admin server.
+// It defaults to the server Config.
+func WithSettings(settings interface{}) Option {
+	return func(server *Server) {
+		server.Settings = settings
+	}
+}
+
+// WithGroup mounts the group controllers under its prefix
+func WithGroup(group *Group) Option {
+	return func(server *Server) {
+		server.Groups = append(server.Groups, group)
+	}
+}
+
+// WithVersioning routes requests without a version prefix to the negotiated version group
+func WithVersioning(versioning *Versioning) Option {
+	return func(server *Server) {
+		server.Versioning = versioning
+	}
+}
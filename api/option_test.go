@@ -5,6 +5,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/raafvargas/wrapit/api"
+	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -59,3 +60,105 @@ func TestWithNoRoute(t *testing.T) {
 	api.WithNoRoute(func(*gin.Context) {})(server)
 	assert.Len(t, server.NoRoute, 1)
 }
+
+func TestWithLogger(t *testing.T) {
+	server := new(api.Server)
+
+	api.WithLogger(logrus.New())(server)
+	assert.NotNil(t, server.Logger)
+}
+
+func TestWithLogSkipPaths(t *testing.T) {
+	server := new(api.Server)
+
+	api.WithLogSkipPaths("/ping")(server)
+	assert.Equal(t, []string{"/ping"}, server.LogSkipPaths)
+}
+
+func TestWithMetricsPath(t *testing.T) {
+	server := new(api.Server)
+
+	api.WithMetricsPath("internal/metrics")(server)
+	assert.Equal(t, "internal/metrics", server.MetricsPath)
+}
+
+func TestWithMetricsBuckets(t *testing.T) {
+	server := new(api.Server)
+
+	api.WithMetricsBuckets(0.1, 1)(server)
+	assert.Equal(t, []float64{0.1, 1}, server.MetricsBuckets)
+}
+
+func TestWithProblemDetails(t *testing.T) {
+	server := new(api.Server)
+
+	api.WithProblemDetails()(server)
+	assert.True(t, server.ProblemDetails)
+}
+
+func TestWithOpenAPI(t *testing.T) {
+	server := new(api.Server)
+
+	api.WithOpenAPI("docs", api.OpenAPIInfo{Title: "title"})(server)
+	assert.Equal(t, "docs", server.OpenAPIPath)
+	assert.Equal(t, "title", server.OpenAPIInfo.Title)
+}
+
+func TestWithConfig(t *testing.T) {
+	server := new(api.Server)
+
+	api.WithConfig(&api.Config{Host: ":8080", H2C: true})(server)
+	assert.Equal(t, ":8080", server.Host)
+	assert.True(t, server.Config.H2C)
+}
+
+func TestWithLiveness(t *testing.T) {
+	server := new(api.Server)
+
+	api.WithLiveness(func(*gin.Context) {})(server)
+	assert.NotNil(t, server.Liveness)
+}
+
+func TestWithReadiness(t *testing.T) {
+	server := new(api.Server)
+
+	api.WithReadiness(func(*gin.Context) {})(server)
+	assert.NotNil(t, server.Readiness)
+}
+
+func TestWithHub(t *testing.T) {
+	server := new(api.Server)
+	hub := api.NewHub()
+
+	api.WithHub(hub)(server)
+	assert.Equal(t, []*api.Hub{hub}, server.Hubs)
+}
+
+func TestWithSignals(t *testing.T) {
+	server := api.New(api.WithSignals())
+	assert.Empty(t, server.Signals)
+}
+
+func TestWithSettings(t *testing.T) {
+	server := new(api.Server)
+	settings := struct{}{}
+
+	api.WithSettings(settings)(server)
+	assert.Equal(t, settings, server.Settings)
+}
+
+func TestWithGroup(t *testing.T) {
+	server := new(api.Server)
+	group := api.NewVersion("v1")
+
+	api.WithGroup(group)(server)
+	assert.Equal(t, []*api.Group{group}, server.Groups)
+}
+
+func TestWithVersioning(t *testing.T) {
+	server := new(api.Server)
+	versioning := &api.Versioning{Default: "v1"}
+
+	api.WithVersioning(versioning)(server)
+	assert.Equal(t, versioning, server.Versioning)
+}
@@ -0,0 +1,134 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raafvargas/wrapit/contract"
+)
+
+var (
+	// DefaultPageLimit ...
+	DefaultPageLimit = 20
+
+	// DefaultMaxPageLimit ...
+	DefaultMaxPageLimit = 100
+)
+
+// ListOptions restricts what clients can ask a list endpoint for.
+// Only the Sortable fields can be sorted and only the Filters params are read as filters.
+type ListOptions struct {
+	DefaultLimit int
+	MaxLimit     int
+	DefaultSort  []contract.SortField
+	Sortable     []string
+	Filters      []string
+}
+
+// ParseListRequest reads page, limit, cursor, sort and the whitelisted filters from the query string.
+// Sort is a comma separated list of fields, prefixed by - for descending order, e.g. sort=-created_at,name.
+// Invalid params return a 422 contract.Error.
+func ParseListRequest(ctx *gin.Context, options *ListOptions) (*contract.ListRequest, error) {
+	if options == nil {
+		options = new(ListOptions)
+	}
+
+	request := &contract.ListRequest{
+		Page:   1,
+		Limit:  options.DefaultLimit,
+		Cursor: ctx.Query("cursor"),
+		Sort:   options.DefaultSort,
+	}
+
+	if request.Limit == 0 {
+		request.Limit = DefaultPageLimit
+	}
+
+	maxLimit := options.MaxLimit
+
+	if maxLimit == 0 {
+		maxLimit = DefaultMaxPageLimit
+	}
+
+	err := contract.NewError(http.StatusUnprocessableEntity)
+
+	if value, ok := ctx.GetQuery("page"); ok {
+		page, e := strconv.Atoi(value)
+
+		if e != nil || page < 1 {
+			addFieldError(err, "page", "min", "1", value)
+		}
+
+		request.Page = page
+	}
+
+	if value, ok := ctx.GetQuery("limit"); ok {
+		limit, e := strconv.Atoi(value)
+
+		switch {
+		case e != nil || limit < 1:
+			addFieldError(err, "limit", "min", "1", value)
+		case limit > maxLimit:
+			addFieldError(err, "limit", "max", strconv.Itoa(maxLimit), value)
+		}
+
+		request.Limit = limit
+	}
+
+	if value := ctx.Query("sort"); value != "" {
+		request.Sort = nil
+
+		for _, field := range strings.Split(value, ",") {
+			sort := contract.SortField{Field: strings.TrimPrefix(field, "-")}
+			sort.Descending = sort.Field != field
+
+			if !contains(options.Sortable, sort.Field) {
+				addFieldError(err, "sort", "oneof", strings.Join(options.Sortable, " "), field)
+				continue
+			}
+
+			request.Sort = append(request.Sort, sort)
+		}
+	}
+
+	for _, filter := range options.Filters {
+		if value, ok := ctx.GetQuery(filter); ok {
+			if request.Filters == nil {
+				request.Filters = make(map[string]string)
+			}
+
+			request.Filters[filter] = value
+		}
+	}
+
+	if len(err.Details) > 0 {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+// BindList parses the list request from the query string.
+// It responds with the error and returns false when it fails.
+func BindList(ctx *gin.Context, options *ListOptions) (*contract.ListRequest, bool) {
+	request, err := ParseListRequest(ctx, options)
+
+	if err != nil {
+		ResolveError(ctx, err)
+		return nil, false
+	}
+
+	return request, true
+}
+
+func addFieldError(err *contract.Error, field, tag, param, value string) {
+	err.Messages = append(err.Messages, "invalid value for field "+field)
+	err.Details = append(err.Details, contract.FieldError{
+		Field: field,
+		Tag:   tag,
+		Param: param,
+		Value: value,
+	})
+}
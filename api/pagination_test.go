@@ -0,0 +1,83 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raafvargas/wrapit/api"
+	"github.com/raafvargas/wrapit/contract"
+	"github.com/stretchr/testify/assert"
+)
+
+func listEngine(result **contract.ListRequest) *gin.Engine {
+	engine := gin.New()
+	options := &api.ListOptions{
+		MaxLimit: 50,
+		Sortable: []string{"name", "created_at"},
+		Filters:  []string{"status"},
+	}
+
+	engine.GET("list", func(ctx *gin.Context) {
+		request, ok := api.BindList(ctx, options)
+		if !ok {
+			return
+		}
+		*result = request
+		ctx.Status(http.StatusNoContent)
+	})
+
+	return engine
+}
+
+func TestParseListRequestDefaults(t *testing.T) {
+	var request *contract.ListRequest
+
+	res := httptest.NewRecorder()
+	listEngine(&request).ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/list", nil))
+
+	assert.Equal(t, http.StatusNoContent, res.Code)
+	assert.Equal(t, 1, request.Page)
+	assert.Equal(t, api.DefaultPageLimit, request.Limit)
+	assert.Empty(t, request.Sort)
+	assert.Empty(t, request.Filters)
+}
+
+func TestParseListRequest(t *testing.T) {
+	var request *contract.ListRequest
+
+	res := httptest.NewRecorder()
+	listEngine(&request).ServeHTTP(res, httptest.NewRequest(http.MethodGet,
+		"/list?page=3&limit=10&sort=-created_at,name&status=active&other=ignored&cursor=abc", nil))
+
+	assert.Equal(t, http.StatusNoContent, res.Code)
+	assert.Equal(t, 3, request.Page)
+	assert.Equal(t, 10, request.Limit)
+	assert.Equal(t, "abc", request.Cursor)
+	assert.Equal(t, []contract.SortField{
+		{Field: "created_at", Descending: true},
+		{Field: "name"},
+	}, request.Sort)
+	assert.Equal(t, map[string]string{"status": "active"}, request.Filters)
+}
+
+func TestParseListRequestInvalid(t *testing.T) {
+	var request *contract.ListRequest
+
+	res := httptest.NewRecorder()
+	listEngine(&request).ServeHTTP(res, httptest.NewRequest(http.MethodGet,
+		"/list?page=0&limit=51&sort=password", nil))
+
+	assert.Equal(t, http.StatusUnprocessableEntity, res.Code)
+	assert.Nil(t, request)
+
+	body := new(contract.Error)
+	assert.NoError(t, json.Unmarshal(res.Body.Bytes(), body))
+	assert.Len(t, body.Details, 3)
+	assert.Equal(t, "page", body.Details[0].Field)
+	assert.Equal(t, "limit", body.Details[1].Field)
+	assert.Equal(t, "max", body.Details[1].Tag)
+	assert.Equal(t, "sort", body.Details[2].Field)
+}
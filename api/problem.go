@@ -0,0 +1,135 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raafvargas/wrapit/contract"
+	"github.com/raafvargas/wrapit/requestid"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.opentelemetry.io/otel/api/trace"
+)
+
+// ProblemDetailsKey is the gin context key enabling problem+json error responses
+var ProblemDetailsKey = "problem_details"
+
+// ProblemType ...
+type ProblemType struct {
+	Type   string
+	Title  string
+	Status int
+}
+
+type problemMapping struct {
+	err     error
+	problem ProblemType
+}
+
+var (
+	problemsMutex = new(sync.RWMutex)
+	problems      = []problemMapping{
+		{
+			err: mongo.ErrNoDocuments,
+			problem: ProblemType{
+				Type:   contract.DefaultProblemType,
+				Title:  http.StatusText(http.StatusNotFound),
+				Status: http.StatusNotFound,
+			},
+		},
+	}
+)
+
+// RegisterProblem maps a sentinel or domain error to a problem type and status
+func RegisterProblem(err error, problem ProblemType) {
+	problemsMutex.Lock()
+	defer problemsMutex.Unlock()
+
+	if problem.Type == "" {
+		problem.Type = contract.DefaultProblemType
+	}
+
+	if problem.Title == "" {
+		problem.Title = http.StatusText(problem.Status)
+	}
+
+	for i, mapping := range problems {
+		if mapping.err == err {
+			problems[i].problem = problem
+			return
+		}
+	}
+
+	problems = append(problems, problemMapping{err: err, problem: problem})
+}
+
+// LookupProblem returns the problem type registered for err
+func LookupProblem(err error) (ProblemType, bool) {
+	problemsMutex.RLock()
+	defer problemsMutex.RUnlock()
+
+	for _, mapping := range problems {
+		if errors.Is(err, mapping.err) {
+			return mapping.problem, true
+		}
+	}
+
+	return ProblemType{}, false
+}
+
+// ProblemDetails enables problem+json error responses for the request
+func ProblemDetails() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		ctx.Set(ProblemDetailsKey, true)
+		ctx.Next()
+	}
+}
+
+// NewProblem builds the problem details for err including the request instance,
+// request id and trace id
+func NewProblem(ctx *gin.Context, err error) *contract.Problem {
+	var problem *contract.Problem
+
+	var contractErr *contract.Error
+
+	if errors.As(err, &contractErr) {
+		problem = contractErr.Problem()
+	} else if problemType, ok := LookupProblem(err); ok {
+		problem = contract.NewProblem(problemType.Status, err.Error())
+		problem.Type = problemType.Type
+		problem.Title = problemType.Title
+	} else {
+		problem = contract.NewProblem(http.StatusInternalServerError, "")
+	}
+
+	problem.Instance = ctx.Request.URL.Path
+
+	if id := GetRequestID(ctx); id != "" {
+		problem.Extensions[requestid.LogField] = id
+	}
+
+	spanContext := trace.SpanFromContext(ctx.Request.Context()).SpanContext()
+
+	if spanContext.IsValid() {
+		problem.Extensions["trace_id"] = spanContext.TraceID.String()
+	}
+
+	return problem
+}
+
+// AbortWithProblem ...
+func AbortWithProblem(ctx *gin.Context, problem *contract.Problem) {
+	ctx.Header("Content-Type", contract.ProblemContentType)
+	ctx.AbortWithStatusJSON(problem.Status, problem)
+}
+
+func wantsProblem(ctx *gin.Context) bool {
+	if !ctx.GetBool(ProblemDetailsKey) {
+		return false
+	}
+
+	format := ctx.NegotiateFormat(contract.ProblemContentType, gin.MIMEJSON)
+
+	return format != gin.MIMEJSON
+}
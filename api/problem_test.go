@@ -0,0 +1,93 @@
+package api_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raafvargas/wrapit/api"
+	"github.com/raafvargas/wrapit/contract"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+var errPaymentRequired = errors.New("payment required")
+
+func problemEngine(err error) *gin.Engine {
+	engine := gin.New()
+	engine.Use(api.RequestID(), api.ProblemDetails())
+	engine.GET("test", func(ctx *gin.Context) {
+		api.ResolveError(ctx, err)
+	})
+
+	return engine
+}
+
+func TestResolveErrorProblem(t *testing.T) {
+	res := httptest.NewRecorder()
+	problemEngine(contract.BusinessError("conflict")).
+		ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/test", nil))
+
+	assert.Equal(t, http.StatusConflict, res.Code)
+	assert.Equal(t, contract.ProblemContentType, res.Header().Get("Content-Type"))
+
+	problem := new(contract.Problem)
+	assert.NoError(t, json.Unmarshal(res.Body.Bytes(), problem))
+	assert.Equal(t, "conflict", problem.Detail)
+	assert.Equal(t, "/test", problem.Instance)
+	assert.NotEmpty(t, problem.Extensions["request_id"])
+}
+
+func TestResolveErrorProblemNegotiation(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set("Accept", "application/json")
+
+	res := httptest.NewRecorder()
+	problemEngine(contract.BusinessError("conflict")).ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusConflict, res.Code)
+	assert.Contains(t, res.Header().Get("Content-Type"), "application/json")
+}
+
+func TestResolveErrorRegisteredProblem(t *testing.T) {
+	api.RegisterProblem(errPaymentRequired, api.ProblemType{
+		Type:   "https://example.com/problems/payment",
+		Status: http.StatusPaymentRequired,
+	})
+
+	res := httptest.NewRecorder()
+	problemEngine(fmt.Errorf("wrapped: %w", errPaymentRequired)).
+		ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/test", nil))
+
+	assert.Equal(t, http.StatusPaymentRequired, res.Code)
+
+	problem := new(contract.Problem)
+	assert.NoError(t, json.Unmarshal(res.Body.Bytes(), problem))
+	assert.Equal(t, "https://example.com/problems/payment", problem.Type)
+	assert.Equal(t, "Payment Required", problem.Title)
+}
+
+func TestResolveErrorNoDocuments(t *testing.T) {
+	res := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(res)
+
+	api.ResolveError(ctx, mongo.ErrNoDocuments)
+
+	assert.Equal(t, http.StatusNotFound, res.Code)
+}
+
+func TestResolveErrorProblemUnexpected(t *testing.T) {
+	res := httptest.NewRecorder()
+	problemEngine(errors.New("unexpected")).
+		ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/test", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, res.Code)
+
+	problem := new(contract.Problem)
+	assert.NoError(t, json.Unmarshal(res.Body.Bytes(), problem))
+	assert.Empty(t, problem.Detail)
+}
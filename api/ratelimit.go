@@ -0,0 +1,163 @@
+package api
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raafvargas/wrapit/contract"
+	"github.com/sirupsen/logrus"
+)
+
+// RateLimitKeyFunc returns the bucket key for the request. An empty key skips the limit.
+type RateLimitKeyFunc func(*gin.Context) string
+
+// RateLimitByIP ...
+func RateLimitByIP(ctx *gin.Context) string {
+	return "ip:" + ctx.ClientIP()
+}
+
+// RateLimitBySubject uses the subject of the auth principal
+func RateLimitBySubject(ctx *gin.Context) string {
+	if subject := GetUserID(ctx); subject != "" {
+		return "sub:" + subject
+	}
+
+	return ""
+}
+
+// RateLimitByRoute ...
+func RateLimitByRoute(ctx *gin.Context) string {
+	return "route:" + ctx.Request.Method + " " + ctx.FullPath()
+}
+
+// RateLimitByHeader uses the value of the given header, such as an API key
+func RateLimitByHeader(header string) RateLimitKeyFunc {
+	return func(ctx *gin.Context) string {
+		if value := ctx.GetHeader(header); value != "" {
+			return "header:" + header + ":" + value
+		}
+
+		return ""
+	}
+}
+
+// RateLimit limits requests using token bucket semantics, responding with 429 when the
+// bucket is empty. Store failures are logged and the request is let through.
+func RateLimit(store contract.RateLimitStore, rate contract.Rate, key RateLimitKeyFunc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		bucket := key(ctx)
+
+		if bucket == "" {
+			ctx.Next()
+			return
+		}
+
+		result, err := store.Take(ctx.Request.Context(), bucket, rate)
+
+		if err != nil {
+			logrus.WithContext(ctx.Request.Context()).
+				WithError(err).
+				Error("rate limit store error")
+			ctx.Next()
+			return
+		}
+
+		ctx.Header("RateLimit-Limit", strconv.Itoa(rate.Burst))
+		ctx.Header("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+		ctx.Header("RateLimit-Reset", strconv.Itoa(ceilSeconds(result.Reset)))
+
+		if !result.Allowed {
+			if result.RetryAfter > 0 {
+				ctx.Header("Retry-After", strconv.Itoa(ceilSeconds(result.RetryAfter)))
+			}
+
+			ResolveError(ctx, contract.NewError(http.StatusTooManyRequests, "rate limit exceeded"))
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// TakeToken refills the bucket for the elapsed time and takes one token from it.
+// It returns the new token count and the result.
+func TakeToken(tokens float64, elapsed time.Duration, rate contract.Rate) (float64, contract.RateLimitResult) {
+	tokens = math.Min(float64(rate.Burst), tokens+elapsed.Seconds()*rate.Limit)
+	allowed := tokens >= 1
+
+	if allowed {
+		tokens--
+	}
+
+	return tokens, rate.Result(tokens, allowed)
+}
+
+// MemoryRateLimitStore keeps the buckets in memory, limiting a single replica
+type MemoryRateLimitStore struct {
+	mutex   *sync.Mutex
+	buckets map[string]*memoryBucket
+	swept   time.Time
+}
+
+type memoryBucket struct {
+	tokens  float64
+	updated time.Time
+	full    time.Time
+}
+
+// NewMemoryRateLimitStore ...
+func NewMemoryRateLimitStore() *MemoryRateLimitStore {
+	return &MemoryRateLimitStore{
+		mutex:   new(sync.Mutex),
+		buckets: make(map[string]*memoryBucket),
+		swept:   time.Now(),
+	}
+}
+
+// Take ...
+func (s *MemoryRateLimitStore) Take(_ context.Context, key string, rate contract.Rate) (contract.RateLimitResult, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := time.Now()
+	s.sweep(now)
+
+	bucket, ok := s.buckets[key]
+
+	if !ok {
+		bucket = &memoryBucket{tokens: float64(rate.Burst), updated: now}
+		s.buckets[key] = bucket
+	}
+
+	tokens, result := TakeToken(bucket.tokens, now.Sub(bucket.updated), rate)
+
+	bucket.tokens = tokens
+	bucket.updated = now
+	bucket.full = now.Add(result.Reset)
+
+	return result, nil
+}
+
+// sweep drops the buckets that are already full again
+func (s *MemoryRateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.swept) < time.Minute {
+		return
+	}
+
+	s.swept = now
+
+	for key, bucket := range s.buckets {
+		if now.After(bucket.full) {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,109 @@
+package api_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raafvargas/wrapit/api"
+	"github.com/raafvargas/wrapit/auth"
+	"github.com/raafvargas/wrapit/contract"
+	"github.com/stretchr/testify/assert"
+)
+
+type failingRateLimitStore struct{}
+
+func (failingRateLimitStore) Take(context.Context, string, contract.Rate) (contract.RateLimitResult, error) {
+	return contract.RateLimitResult{}, errors.New("store unavailable")
+}
+
+func TestRateLimit(t *testing.T) {
+	engine := gin.New()
+	engine.Use(api.RateLimit(api.NewMemoryRateLimitStore(), contract.Rate{Limit: 1, Burst: 2}, api.RateLimitByIP))
+	engine.GET("test", func(ctx *gin.Context) {
+		ctx.Status(http.StatusNoContent)
+	})
+
+	for i := 0; i < 2; i++ {
+		res := httptest.NewRecorder()
+		engine.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/test", nil))
+
+		assert.Equal(t, http.StatusNoContent, res.Code)
+		assert.Equal(t, "2", res.Header().Get("RateLimit-Limit"))
+	}
+
+	res := httptest.NewRecorder()
+	engine.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/test", nil))
+
+	assert.Equal(t, http.StatusTooManyRequests, res.Code)
+	assert.Equal(t, "0", res.Header().Get("RateLimit-Remaining"))
+	assert.Equal(t, "1", res.Header().Get("Retry-After"))
+}
+
+func TestRateLimitSkipsEmptyKey(t *testing.T) {
+	engine := gin.New()
+	engine.Use(api.RateLimit(api.NewMemoryRateLimitStore(), contract.Rate{Burst: 0}, api.RateLimitBySubject))
+	engine.GET("test", func(ctx *gin.Context) {
+		ctx.Status(http.StatusNoContent)
+	})
+
+	res := httptest.NewRecorder()
+	engine.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/test", nil))
+
+	assert.Equal(t, http.StatusNoContent, res.Code)
+}
+
+func TestRateLimitStoreError(t *testing.T) {
+	engine := gin.New()
+	engine.Use(api.RateLimit(failingRateLimitStore{}, contract.PerMinute(1), api.RateLimitByRoute))
+	engine.GET("test", func(ctx *gin.Context) {
+		ctx.Status(http.StatusNoContent)
+	})
+
+	res := httptest.NewRecorder()
+	engine.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/test", nil))
+
+	assert.Equal(t, http.StatusNoContent, res.Code)
+}
+
+func TestRateLimitByHeader(t *testing.T) {
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+
+	assert.Empty(t, api.RateLimitByHeader("X-API-Key")(ctx))
+
+	ctx.Request.Header.Set("X-API-Key", "key")
+	assert.Equal(t, "header:X-API-Key:key", api.RateLimitByHeader("X-API-Key")(ctx))
+}
+
+func TestTakeToken(t *testing.T) {
+	rate := contract.Rate{Limit: 2, Burst: 4}
+
+	tokens, result := api.TakeToken(0, time.Second, rate)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, float64(1), tokens)
+	assert.Equal(t, 1500*time.Millisecond, result.Reset)
+
+	tokens, result = api.TakeToken(0.5, 0, rate)
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 0.5, tokens)
+	assert.Equal(t, 250*time.Millisecond, result.RetryAfter)
+
+	_, result = api.TakeToken(0, time.Hour, contract.Rate{Burst: 1})
+	assert.False(t, result.Allowed)
+	assert.Equal(t, time.Duration(0), result.RetryAfter)
+	assert.Equal(t, time.Duration(0), result.Reset)
+}
+
+func TestRateLimitBySubject(t *testing.T) {
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	assert.Empty(t, api.RateLimitBySubject(ctx))
+
+	auth.SetPrincipal(ctx, &auth.Principal{Subject: "user"})
+	assert.Equal(t, "sub:user", api.RateLimitBySubject(ctx))
+	assert.Equal(t, "user", api.GetUserID(ctx))
+}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"runtime/debug"
+	"syscall"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/raafvargas/wrapit/contract"
+	"github.com/raafvargas/wrapit/requestid"
+	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/api/trace"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// Recovery recovers from panics, logging the stack, recording the error on the
+// active span and responding with a contract.Error or problem details body.
+// http.ErrAbortHandler is re-panicked and broken connections get no response.
+func Recovery(logger *logrus.Logger, registerer prometheus.Registerer) gin.HandlerFunc {
+	panics := registerCollector(registerer, prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_panics_total",
+		Help: "Total number of recovered HTTP handler panics.",
+	}, []string{"method", "route"})).(*prometheus.CounterVec)
+
+	return func(ctx *gin.Context) {
+		defer func() {
+			recovered := recover()
+
+			if recovered == nil {
+				return
+			}
+
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			err, ok := recovered.(error)
+
+			if !ok {
+				err = fmt.Errorf("%v", recovered)
+			}
+
+			// the client is gone, the response can't be written
+			if errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET) {
+				logger.WithError(err).
+					WithField(requestid.LogField, GetRequestID(ctx)).
+					Warn("connection closed by the client")
+
+				ctx.Error(err)
+				ctx.Abort()
+				return
+			}
+
+			route := ctx.FullPath()
+
+			if route == "" {
+				route = UnmatchedRoute
+			}
+
+			panics.WithLabelValues(ctx.Request.Method, route).Inc()
+
+			span := trace.SpanFromContext(ctx.Request.Context())
+			span.RecordError(ctx.Request.Context(), err, trace.WithErrorStatus(codes.Internal))
+			span.SetStatus(codes.Internal, "panic")
+
+			id := GetRequestID(ctx)
+
+			logger.WithError(err).
+				WithField(requestid.LogField, id).
+				WithField("stack", string(debug.Stack())).
+				Error("request panicked")
+
+			ctx.Error(err)
+
+			message := contract.NewError(http.StatusInternalServerError, http.StatusText(http.StatusInternalServerError))
+			message.RequestID = id
+
+			if wantsProblem(ctx) {
+				AbortWithProblem(ctx, NewProblem(ctx, message))
+				return
+			}
+
+			ctx.AbortWithStatusJSON(http.StatusInternalServerError, message)
+		}()
+
+		ctx.Next()
+	}
+}
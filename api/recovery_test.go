@@ -0,0 +1,96 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/raafvargas/wrapit/api"
+	"github.com/raafvargas/wrapit/contract"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecovery(t *testing.T) {
+	logger, hook := test.NewNullLogger()
+	registry := prometheus.NewRegistry()
+
+	engine := gin.New()
+	engine.Use(api.RequestID(), api.Recovery(logger, registry))
+	engine.GET("panic", func(ctx *gin.Context) {
+		panic("boom")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/panic", nil)
+	req.Header.Set("X-Request-ID", "id")
+
+	res := httptest.NewRecorder()
+	engine.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusInternalServerError, res.Code)
+
+	body := new(contract.Error)
+	assert.NoError(t, json.Unmarshal(res.Body.Bytes(), body))
+	assert.Equal(t, "id", body.RequestID)
+
+	entry := hook.LastEntry()
+	assert.Equal(t, "request panicked", entry.Message)
+	assert.Contains(t, entry.Data["stack"], "runtime/debug.Stack")
+
+	count, err := testutil.GatherAndCount(registry, "http_panics_total")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+}
+
+func TestRecoveryProblem(t *testing.T) {
+	logger, _ := test.NewNullLogger()
+
+	engine := gin.New()
+	engine.Use(api.ProblemDetails(), api.Recovery(logger, prometheus.NewRegistry()))
+	engine.GET("panic", func(ctx *gin.Context) {
+		panic("boom")
+	})
+
+	res := httptest.NewRecorder()
+	engine.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	assert.Equal(t, http.StatusInternalServerError, res.Code)
+	assert.Equal(t, contract.ProblemContentType, res.Header().Get("Content-Type"))
+}
+
+func TestRecoveryAbortHandler(t *testing.T) {
+	logger, _ := test.NewNullLogger()
+
+	engine := gin.New()
+	engine.Use(api.Recovery(logger, prometheus.NewRegistry()))
+	engine.GET("abort", func(ctx *gin.Context) {
+		panic(http.ErrAbortHandler)
+	})
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		engine.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/abort", nil))
+	})
+}
+
+func TestRecoveryBrokenPipe(t *testing.T) {
+	logger, hook := test.NewNullLogger()
+
+	engine := gin.New()
+	engine.Use(api.Recovery(logger, prometheus.NewRegistry()))
+	engine.GET("write", func(ctx *gin.Context) {
+		panic(&net.OpError{Op: "write", Net: "tcp", Err: os.NewSyscallError("write", syscall.EPIPE)})
+	})
+
+	res := httptest.NewRecorder()
+	engine.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/write", nil))
+
+	assert.Empty(t, res.Body.String())
+	assert.Equal(t, "connection closed by the client", hook.LastEntry().Message)
+}
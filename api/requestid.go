@@ -0,0 +1,34 @@
+package api
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/raafvargas/wrapit/requestid"
+)
+
+// RequestIDKey is the gin context key holding the request id
+var RequestIDKey = "request_id"
+
+// RequestID reads the request id header or generates a new one when it's missing or
+// invalid, storing it in the request context and echoing it in the response
+func RequestID() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		id := ctx.GetHeader(requestid.HeaderName)
+
+		if !requestid.Valid(id) {
+			id = requestid.New()
+		}
+
+		ctx.Set(RequestIDKey, id)
+		ctx.Request = ctx.Request.WithContext(
+			requestid.NewContext(ctx.Request.Context(), id),
+		)
+		ctx.Header(requestid.HeaderName, id)
+
+		ctx.Next()
+	}
+}
+
+// GetRequestID ...
+func GetRequestID(ctx *gin.Context) string {
+	return ctx.GetString(RequestIDKey)
+}
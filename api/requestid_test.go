@@ -0,0 +1,64 @@
+package api_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raafvargas/wrapit/api"
+	"github.com/raafvargas/wrapit/requestid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	var fromContext string
+
+	engine := gin.New()
+	engine.Use(api.RequestID())
+	engine.GET("test", func(ctx *gin.Context) {
+		fromContext = requestid.FromContext(ctx.Request.Context())
+		ctx.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set(requestid.HeaderName, "id")
+
+	res := httptest.NewRecorder()
+	engine.ServeHTTP(res, req)
+
+	assert.Equal(t, "id", res.Header().Get(requestid.HeaderName))
+	assert.Equal(t, "id", fromContext)
+}
+
+func TestRequestIDGenerated(t *testing.T) {
+	engine := gin.New()
+	engine.Use(api.RequestID())
+	engine.GET("test", func(ctx *gin.Context) {
+		assert.NotEmpty(t, api.GetRequestID(ctx))
+		ctx.Status(http.StatusOK)
+	})
+
+	res := httptest.NewRecorder()
+	engine.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/test", nil))
+
+	assert.NotEmpty(t, res.Header().Get(requestid.HeaderName))
+}
+
+func TestRequestIDInvalid(t *testing.T) {
+	engine := gin.New()
+	engine.Use(api.RequestID())
+	engine.GET("test", func(ctx *gin.Context) {
+		ctx.Status(http.StatusOK)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/test", nil)
+	req.Header.Set(requestid.HeaderName, "<script>")
+
+	res := httptest.NewRecorder()
+	engine.ServeHTTP(res, req)
+
+	id := res.Header().Get(requestid.HeaderName)
+	assert.NotEqual(t, "<script>", id)
+	assert.True(t, requestid.Valid(id))
+}
@@ -0,0 +1,248 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raafvargas/wrapit/contract"
+)
+
+var (
+	// DefaultCORSMethods ...
+	DefaultCORSMethods = []string{
+		http.MethodGet, http.MethodPost, http.MethodPut,
+		http.MethodPatch, http.MethodDelete, http.MethodHead,
+	}
+
+	// DefaultCORSHeaders ...
+	DefaultCORSHeaders = []string{"Origin", "Content-Type", "Accept", "Authorization", "X-Request-ID"}
+
+	// ErrRequestBodyTooLarge is returned reading a body past the limit of MaxBodySize
+	ErrRequestBodyTooLarge = errors.New("request body too large")
+)
+
+// CORSConfig ...
+type CORSConfig struct {
+	AllowOrigins     []string      `yaml:"allow_origins"`
+	AllowMethods     []string      `yaml:"allow_methods"`
+	AllowHeaders     []string      `yaml:"allow_headers"`
+	ExposeHeaders    []string      `yaml:"expose_headers"`
+	AllowCredentials bool          `yaml:"allow_credentials"`
+	MaxAge           time.Duration `yaml:"max_age"`
+}
+
+// SecurityHeadersConfig ...
+type SecurityHeadersConfig struct {
+	HSTSMaxAge            time.Duration `yaml:"hsts_max_age"`
+	HSTSIncludeSubdomains bool          `yaml:"hsts_include_subdomains"`
+	ContentSecurityPolicy string        `yaml:"content_security_policy"`
+	FrameOptions          string        `yaml:"frame_options"`
+	ReferrerPolicy        string        `yaml:"referrer_policy"`
+}
+
+// CORS answers preflight requests and sets the CORS headers for allowed origins.
+// Origins may use a single * wildcard such as https://*.example.com.
+func CORS(config *CORSConfig) gin.HandlerFunc {
+	methods := config.AllowMethods
+
+	if len(methods) == 0 {
+		methods = DefaultCORSMethods
+	}
+
+	headers := config.AllowHeaders
+
+	if len(headers) == 0 {
+		headers = DefaultCORSHeaders
+	}
+
+	allowMethods := strings.Join(methods, ", ")
+	allowHeaders := strings.Join(headers, ", ")
+	exposeHeaders := strings.Join(config.ExposeHeaders, ", ")
+	maxAge := strconv.Itoa(int(config.MaxAge.Seconds()))
+
+	return func(ctx *gin.Context) {
+		origin := ctx.GetHeader("Origin")
+
+		if origin == "" {
+			ctx.Next()
+			return
+		}
+
+		ctx.Writer.Header().Add("Vary", "Origin")
+
+		if !allowedOrigin(config.AllowOrigins, origin) {
+			if isPreflight(ctx) {
+				ctx.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+
+			ctx.Next()
+			return
+		}
+
+		if contains(config.AllowOrigins, "*") && !config.AllowCredentials {
+			ctx.Header("Access-Control-Allow-Origin", "*")
+		} else {
+			ctx.Header("Access-Control-Allow-Origin", origin)
+		}
+
+		if config.AllowCredentials {
+			ctx.Header("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !isPreflight(ctx) {
+			if exposeHeaders != "" {
+				ctx.Header("Access-Control-Expose-Headers", exposeHeaders)
+			}
+
+			ctx.Next()
+			return
+		}
+
+		ctx.Header("Access-Control-Allow-Methods", allowMethods)
+		ctx.Header("Access-Control-Allow-Headers", allowHeaders)
+
+		if config.MaxAge > 0 {
+			ctx.Header("Access-Control-Max-Age", maxAge)
+		}
+
+		ctx.AbortWithStatus(http.StatusNoContent)
+	}
+}
+
+// SecurityHeaders sets HSTS, CSP, frame options, referrer policy and nosniff headers.
+// HSTS is only sent over TLS.
+func SecurityHeaders(config *SecurityHeadersConfig) gin.HandlerFunc {
+	frameOptions := config.FrameOptions
+
+	if frameOptions == "" {
+		frameOptions = "DENY"
+	}
+
+	hsts := ""
+
+	if config.HSTSMaxAge > 0 {
+		hsts = fmt.Sprintf("max-age=%d", int(config.HSTSMaxAge.Seconds()))
+
+		if config.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+	}
+
+	return func(ctx *gin.Context) {
+		header := ctx.Writer.Header()
+
+		header.Set("X-Content-Type-Options", "nosniff")
+		header.Set("X-Frame-Options", frameOptions)
+
+		if config.ContentSecurityPolicy != "" {
+			header.Set("Content-Security-Policy", config.ContentSecurityPolicy)
+		}
+
+		if config.ReferrerPolicy != "" {
+			header.Set("Referrer-Policy", config.ReferrerPolicy)
+		}
+
+		if hsts != "" && ctx.Request.TLS != nil {
+			header.Set("Strict-Transport-Security", hsts)
+		}
+
+		ctx.Next()
+	}
+}
+
+// MaxBodySize rejects requests with bodies larger than limit bytes with 413
+func MaxBodySize(limit int64) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if ctx.Request.ContentLength > limit {
+			ResolveError(ctx, requestBodyTooLarge())
+			return
+		}
+
+		ctx.Request.Body = limitBody(ctx.Request.Body, limit)
+		ctx.Next()
+	}
+}
+
+// limitBody fails reads past limit bytes with ErrRequestBodyTooLarge
+func limitBody(body io.ReadCloser, limit int64) io.ReadCloser {
+	return &limitedBody{ReadCloser: body, remaining: limit}
+}
+
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining < 0 {
+		return 0, ErrRequestBodyTooLarge
+	}
+
+	// read one byte past the limit to tell a body of exactly limit bytes from a larger one
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+
+	n, err := b.ReadCloser.Read(p)
+
+	if int64(n) > b.remaining {
+		n = int(b.remaining)
+		b.remaining = -1
+
+		return n, ErrRequestBodyTooLarge
+	}
+
+	b.remaining -= int64(n)
+
+	return n, err
+}
+
+func requestBodyTooLarge() *contract.Error {
+	return contract.NewError(http.StatusRequestEntityTooLarge, "request body too large")
+}
+
+func isPreflight(ctx *gin.Context) bool {
+	return ctx.Request.Method == http.MethodOptions &&
+		ctx.GetHeader("Access-Control-Request-Method") != ""
+}
+
+func allowedOrigin(allowed []string, origin string) bool {
+	for _, pattern := range allowed {
+		if pattern == "*" || pattern == origin {
+			return true
+		}
+
+		i := strings.Index(pattern, "*")
+
+		if i < 0 {
+			continue
+		}
+
+		prefix, suffix := pattern[:i], pattern[i+1:]
+
+		if len(origin) >= len(prefix)+len(suffix) &&
+			strings.HasPrefix(origin, prefix) &&
+			strings.HasSuffix(origin, suffix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,150 @@
+package api_test
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raafvargas/wrapit/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func securityEngine(config *api.Config) *gin.Engine {
+	server := api.New(
+		api.WithConfig(config),
+		api.WithController(new(bindingController)),
+	)
+
+	return server.Engine
+}
+
+type bindingController struct{}
+
+func (*bindingController) RegisterRoutes(router *gin.RouterGroup) {
+	router.POST("items", func(ctx *gin.Context) {
+		req := new(bindingRequest)
+		if !api.BindJSON(ctx, req) {
+			return
+		}
+		ctx.Status(http.StatusNoContent)
+	})
+}
+
+func TestCORSPreflight(t *testing.T) {
+	engine := securityEngine(&api.Config{
+		CORS: &api.CORSConfig{
+			AllowOrigins:     []string{"https://*.example.com"},
+			AllowCredentials: true,
+			MaxAge:           time.Hour,
+		},
+	})
+
+	req := httptest.NewRequest(http.MethodOptions, "/items", nil)
+	req.Header.Set("Origin", "https://app.example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+
+	res := httptest.NewRecorder()
+	engine.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusNoContent, res.Code)
+	assert.Equal(t, "https://app.example.com", res.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "true", res.Header().Get("Access-Control-Allow-Credentials"))
+	assert.Equal(t, "3600", res.Header().Get("Access-Control-Max-Age"))
+	assert.Contains(t, res.Header().Get("Access-Control-Allow-Methods"), http.MethodPost)
+}
+
+func TestCORSDisallowedOrigin(t *testing.T) {
+	engine := securityEngine(&api.Config{
+		CORS: &api.CORSConfig{
+			AllowOrigins: []string{"https://*.example.com"},
+		},
+	})
+
+	req := httptest.NewRequest(http.MethodOptions, "/items", nil)
+	req.Header.Set("Origin", "https://example.org")
+	req.Header.Set("Access-Control-Request-Method", http.MethodPost)
+
+	res := httptest.NewRecorder()
+	engine.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusForbidden, res.Code)
+	assert.Empty(t, res.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestCORSWildcard(t *testing.T) {
+	engine := securityEngine(&api.Config{
+		CORS: &api.CORSConfig{
+			AllowOrigins:  []string{"*"},
+			ExposeHeaders: []string{"X-Request-ID"},
+		},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	req.Header.Set("Origin", "https://example.org")
+
+	res := httptest.NewRecorder()
+	engine.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "*", res.Header().Get("Access-Control-Allow-Origin"))
+	assert.Equal(t, "X-Request-ID", res.Header().Get("Access-Control-Expose-Headers"))
+}
+
+func TestSecurityHeaders(t *testing.T) {
+	engine := securityEngine(&api.Config{
+		SecurityHeaders: &api.SecurityHeadersConfig{
+			HSTSMaxAge:            time.Hour,
+			HSTSIncludeSubdomains: true,
+			ContentSecurityPolicy: "default-src 'self'",
+		},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	req.TLS = &tls.ConnectionState{}
+
+	res := httptest.NewRecorder()
+	engine.ServeHTTP(res, req)
+
+	assert.Equal(t, "nosniff", res.Header().Get("X-Content-Type-Options"))
+	assert.Equal(t, "DENY", res.Header().Get("X-Frame-Options"))
+	assert.Equal(t, "default-src 'self'", res.Header().Get("Content-Security-Policy"))
+	assert.Equal(t, "max-age=3600; includeSubDomains", res.Header().Get("Strict-Transport-Security"))
+}
+
+func TestMaxBodySize(t *testing.T) {
+	engine := securityEngine(&api.Config{
+		MaxBodyBytes: 16,
+	})
+
+	body := `{"name":"` + strings.Repeat("a", 32) + `"}`
+
+	res := httptest.NewRecorder()
+	engine.ServeHTTP(res, httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(body)))
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, res.Code)
+
+	req := httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(body))
+	req.ContentLength = -1
+
+	res = httptest.NewRecorder()
+	engine.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, res.Code)
+
+	res = httptest.NewRecorder()
+	engine.ServeHTTP(res, httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(`{"name":"a"}`)))
+
+	assert.Equal(t, http.StatusNoContent, res.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/items", strings.NewReader(`{"name":"abcde"}`))
+	req.ContentLength = -1
+
+	res = httptest.NewRecorder()
+	engine.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusNoContent, res.Code)
+}
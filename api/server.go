@@ -2,21 +2,41 @@ package api
 
 import (
 	"context"
+	"crypto/tls"
 	"net/http"
 	"os"
 	"os/signal"
+	"sync/atomic"
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
 
 	gintrace "go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin"
 )
 
 // Config ...
 type Config struct {
-	Host string `yaml:"host"`
+	Host              string        `yaml:"host"`
+	TLS               *TLSConfig    `yaml:"tls"`
+	H2C               bool          `yaml:"h2c"`
+	ReadTimeout       time.Duration `yaml:"read_timeout"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout"`
+	WriteTimeout      time.Duration `yaml:"write_timeout"`
+	IdleTimeout       time.Duration `yaml:"idle_timeout"`
+	MaxHeaderBytes    int           `yaml:"max_header_bytes"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout"`
+	PreStopDelay      time.Duration `yaml:"pre_stop_delay"`
+
+	Admin *AdminConfig `yaml:"admin"`
+
+	CORS            *CORSConfig            `yaml:"cors"`
+	SecurityHeaders *SecurityHeadersConfig `yaml:"security_headers"`
+	MaxBodyBytes    int64                  `yaml:"max_body_bytes"`
 }
 
 var (
@@ -24,11 +44,15 @@ var (
 	DefaultHealthz = func(ctx *gin.Context) {
 		ctx.Status(http.StatusOK)
 	}
+
+	// DefaultShutdownTimeout ...
+	DefaultShutdownTimeout = 5 * time.Second
 )
 
 // Server ...
 type Server struct {
-	Engine *gin.Engine
+	Engine  *gin.Engine
+	handler http.Handler
 
 	Handlers    []gin.HandlerFunc
 	NoRoute     []gin.HandlerFunc
@@ -36,60 +60,243 @@ type Server struct {
 	ServiceName string
 	Host        string
 	Controllers []Controller
+	Groups      []*Group
+	Versioning  *Versioning
 	Healthz     gin.HandlerFunc
+	Liveness    gin.HandlerFunc
+	Readiness   gin.HandlerFunc
 	Shutdown    chan os.Signal
+	Signals     []os.Signal
+	Config      *Config
+
+	ready int32
+
+	Logger       *logrus.Logger
+	LogSkipPaths []string
+
+	MetricsPath       string
+	MetricsBuckets    []float64
+	MetricsRegisterer prometheus.Registerer
+
+	ProblemDetails bool
+
+	OpenAPIPath string
+	OpenAPIInfo OpenAPIInfo
+	OpenAPI     *OpenAPI
+
+	Hubs []*Hub
+
+	Admin    *AdminServer
+	Settings interface{}
+
+	err error
 }
 
 // New ....
+// When another server registered other metrics buckets in the same registerer,
+// the server serves without HTTP metrics and Run returns ErrMetricsBuckets.
 func New(opts ...Option) *Server {
 	server := &Server{}
 	server.Handlers = []gin.HandlerFunc{}
 	server.NoRoute = []gin.HandlerFunc{}
 	server.Controllers = []Controller{}
 	server.Shutdown = make(chan os.Signal)
+	server.Signals = []os.Signal{os.Interrupt}
 	server.Healthz = DefaultHealthz
+	server.Liveness = DefaultHealthz
+	server.Readiness = DefaultHealthz
+	server.Config = &Config{}
 	server.Metrics = promhttp.Handler()
+	server.Logger = logrus.StandardLogger()
+	server.LogSkipPaths = DefaultLogSkipPaths
+	server.MetricsPath = DefaultMetricsPath
+	server.MetricsBuckets = DefaultMetricsBuckets
+	server.MetricsRegisterer = prometheus.DefaultRegisterer
 
 	for _, opt := range opts {
 		opt(server)
 	}
 
-	server.Engine = gin.Default()
+	skipPaths := append([]string{}, server.LogSkipPaths...)
+
+	if server.MetricsPath != "" {
+		skipPaths = append(skipPaths, server.MetricsPath)
+	}
+
+	server.Engine = gin.New()
 
 	server.Engine.Use(
+		RequestID(),
 		gintrace.Middleware(server.ServiceName),
+		Logger(server.Logger, skipPaths...),
 	)
 
+	if metrics, err := NewHTTPMetrics(server.MetricsRegisterer, server.MetricsBuckets); err != nil {
+		server.err = err
+	} else {
+		server.Engine.Use(metrics.HTTP())
+	}
+
+	server.Engine.Use(Recovery(server.Logger, server.MetricsRegisterer))
+
+	if server.ProblemDetails {
+		server.Engine.Use(ProblemDetails())
+	}
+
+	if server.Config.CORS != nil {
+		server.Engine.Use(CORS(server.Config.CORS))
+	}
+
+	if server.Config.SecurityHeaders != nil {
+		server.Engine.Use(SecurityHeaders(server.Config.SecurityHeaders))
+	}
+
+	if server.Config.MaxBodyBytes > 0 {
+		server.Engine.Use(MaxBodySize(server.Config.MaxBodyBytes))
+	}
+
 	server.Engine.GET("healthz", server.Healthz)
-	server.Engine.GET("metrics", func(ctx *gin.Context) {
-		server.Metrics.ServeHTTP(ctx.Writer, ctx.Request)
-	})
+	server.Engine.GET("livez", server.Liveness)
+	server.Engine.GET("readyz", server.readyz)
+
+	if server.MetricsPath != "" {
+		server.Engine.GET(server.MetricsPath, func(ctx *gin.Context) {
+			server.Metrics.ServeHTTP(ctx.Writer, ctx.Request)
+		})
+	}
 
 	server.Engine.NoRoute(server.NoRoute...)
-	server.Engine.Use(server.Engine.Handlers...)
+	server.Engine.Use(server.Handlers...)
 
 	for _, ctrl := range server.Controllers {
 		ctrl.RegisterRoutes(&server.Engine.RouterGroup)
 	}
 
+	for _, group := range server.Groups {
+		group.register(server.Engine)
+	}
+
+	if server.OpenAPIPath != "" {
+		server.registerOpenAPI()
+	}
+
+	server.handler = server.Engine
+
+	if server.Versioning != nil {
+		server.handler = newVersionRouter(server.Engine, server.Versioning, server.Groups)
+	}
+
+	if server.Config.Admin != nil && server.Config.Admin.Host != "" {
+		server.Admin = server.adminServer()
+	}
+
 	return server
 }
 
-// Run ...
-func (server *Server) Run() error {
-	srv := http.Server{
-		Addr:    server.Host,
-		Handler: server.Engine,
+func (server *Server) adminServer() *AdminServer {
+	settings := server.Settings
+
+	if settings == nil {
+		settings = server.Config
 	}
 
-	signal.Notify(server.Shutdown, os.Interrupt)
+	return NewAdminServer(server.Config.Admin.Host,
+		WithAdminLogger(server.Logger),
+		WithAdminMetrics(server.Metrics),
+		WithAdminHealthz(server.Healthz),
+		WithAdminLiveness(server.Liveness),
+		WithAdminReadiness(server.readyz),
+		WithAdminSettings(settings),
+	)
+}
+
+func (server *Server) registerOpenAPI() {
+	routes := []RouteDescription{}
+
+	for _, ctrl := range server.Controllers {
+		if described, ok := ctrl.(DescribedController); ok {
+			routes = append(routes, described.Describe()...)
+		}
+	}
+
+	for _, group := range server.Groups {
+		routes = append(routes, group.describe()...)
+	}
+
+	server.OpenAPI = NewOpenAPI(server.OpenAPIInfo, routes)
+
+	handler := OpenAPIHandler(server.OpenAPI)
+	group := server.Engine.Group(server.OpenAPIPath)
+
+	group.GET("", handler)
+	group.GET("openapi.json", handler)
+	group.GET("openapi.yaml", handler)
+	group.GET("ui/*asset", handler)
+}
+
+// Run starts the server, and the admin server when configured, blocking until it stops
+// and drains. Shutdown is triggered by one of the Signals, the Shutdown channel, the
+// cancellation of ctx or an admin server failure.
+func (server *Server) Run(ctx context.Context) error {
+	srv, err := server.httpServer()
+
+	if err != nil {
+		return err
+	}
+
+	if len(server.Signals) > 0 {
+		signal.Notify(server.Shutdown, server.Signals...)
+		defer signal.Stop(server.Shutdown)
+	}
+
+	adminCtx, stopAdmin := context.WithCancel(context.Background())
+	defer stopAdmin()
+
+	adminErr := make(chan error, 1)
+
+	if server.Admin != nil {
+		go func() {
+			adminErr <- server.Admin.Run(adminCtx)
+		}()
+	}
+
+	done := make(chan struct{})
+	drained := make(chan struct{})
 
 	go func() {
-		<-server.Shutdown
+		defer close(drained)
 
-		logrus.Info("waiting 5 seconds to stop the server")
+		select {
+		case <-server.Shutdown:
+		case <-ctx.Done():
+		case err := <-adminErr:
+			adminErr <- err
+			logrus.WithError(err).Error("admin server stopped, shutting down")
+		case <-done:
+			return
+		}
+
+		atomic.StoreInt32(&server.ready, 0)
 
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		if delay := server.Config.PreStopDelay; delay > 0 {
+			logrus.Infof("not ready, waiting %s before draining connections", delay)
+			time.Sleep(delay)
+		}
+
+		timeout := server.Config.ShutdownTimeout
+
+		if timeout == 0 {
+			timeout = DefaultShutdownTimeout
+		}
+
+		logrus.Infof("waiting %s to stop the server", timeout)
+
+		// streaming clients never go idle, disconnect them so the server can drain
+		for _, hub := range server.Hubs {
+			hub.Close()
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
 		defer cancel()
 
 		if err := srv.Shutdown(ctx); err != nil {
@@ -98,9 +305,87 @@ func (server *Server) Run() error {
 		}
 	}()
 
-	if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+	atomic.StoreInt32(&server.ready, 1)
+
+	if srv.TLSConfig != nil {
+		err = srv.ListenAndServeTLS("", "")
+	} else {
+		err = srv.ListenAndServe()
+	}
+
+	// ListenAndServe returns as soon as Shutdown starts, wait for the connections to drain
+	close(done)
+	<-drained
+
+	if err != nil && err != http.ErrServerClosed {
 		return err
 	}
 
+	if server.Admin != nil {
+		stopAdmin()
+		return <-adminErr
+	}
+
 	return nil
 }
+
+// ServeHTTP serves the engine, negotiating the version of unversioned paths when Versioning is set
+func (server *Server) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	server.handler.ServeHTTP(w, req)
+}
+
+// Ready reports whether the server is running and not draining
+func (server *Server) Ready() bool {
+	return atomic.LoadInt32(&server.ready) == 1
+}
+
+func (server *Server) readyz(ctx *gin.Context) {
+	if !server.Ready() {
+		ctx.AbortWithStatus(http.StatusServiceUnavailable)
+		return
+	}
+
+	server.Readiness(ctx)
+}
+
+func (server *Server) httpServer() (*http.Server, error) {
+	if server.err != nil {
+		return nil, server.err
+	}
+
+	config := server.Config
+
+	srv := &http.Server{
+		Addr:              server.Host,
+		Handler:           server,
+		ReadTimeout:       config.ReadTimeout,
+		ReadHeaderTimeout: config.ReadHeaderTimeout,
+		WriteTimeout:      config.WriteTimeout,
+		IdleTimeout:       config.IdleTimeout,
+		MaxHeaderBytes:    config.MaxHeaderBytes,
+	}
+
+	if config.TLS.Enabled() {
+		reloader, err := NewCertificateReloader(config.TLS)
+
+		if err != nil {
+			return nil, err
+		}
+
+		srv.TLSConfig = &tls.Config{
+			MinVersion:     tls.VersionTLS12,
+			GetCertificate: reloader.GetCertificate,
+			NextProtos:     []string{"h2", "http/1.1"},
+		}
+
+		return srv, nil
+	}
+
+	if config.H2C {
+		srv.Handler = h2c.NewHandler(server, &http2.Server{
+			IdleTimeout: config.IdleTimeout,
+		})
+	}
+
+	return srv, nil
+}
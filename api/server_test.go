@@ -2,7 +2,9 @@ package api_test
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
@@ -10,9 +12,12 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/raafvargas/wrapit/api"
 	"github.com/raafvargas/wrapit/configuration"
+	"github.com/sirupsen/logrus/hooks/test"
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/http2"
 )
 
 type testController struct{}
@@ -44,6 +49,21 @@ func TestServer(t *testing.T) {
 	assert.Equal(t, http.StatusNoContent, res.StatusCode)
 }
 
+func TestServerHandlers(t *testing.T) {
+	server := api.New(
+		api.WithHandler(func(ctx *gin.Context) {
+			ctx.Header("X-Handler", "true")
+		}),
+		api.WithController(new(testController)),
+	)
+
+	res := httptest.NewRecorder()
+	server.Engine.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/test", nil))
+
+	assert.Equal(t, http.StatusNoContent, res.Code)
+	assert.Equal(t, "true", res.Header().Get("X-Handler"))
+}
+
 func TestServerHealthz(t *testing.T) {
 	server := api.New()
 
@@ -78,6 +98,68 @@ func TestServerMetrics(t *testing.T) {
 	assert.Equal(t, http.StatusOK, res.StatusCode)
 }
 
+func TestServerMetricsPathNotLogged(t *testing.T) {
+	logger, hook := test.NewNullLogger()
+
+	server := api.New(
+		api.WithLogger(logger),
+		api.WithMetricsPath("internal/metrics"),
+	)
+
+	res := httptest.NewRecorder()
+	server.Engine.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/internal/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Empty(t, hook.AllEntries())
+}
+
+func TestServerMetricsDisabled(t *testing.T) {
+	server := api.New(
+		api.WithMetricsPath(""),
+	)
+
+	ts := httptest.NewServer(server.Engine)
+	defer ts.Close()
+
+	res, err := http.Get(fmt.Sprintf("%s/metrics", ts.URL))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer res.Body.Close()
+
+	assert.Equal(t, http.StatusNotFound, res.StatusCode)
+}
+
+func TestServerMetricsRegisterer(t *testing.T) {
+	registry := prometheus.NewRegistry()
+
+	server := api.New(
+		api.WithMetricsRegisterer(registry),
+		api.WithMetricsBuckets(1, 2),
+	)
+
+	res := httptest.NewRecorder()
+	server.Engine.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	res = httptest.NewRecorder()
+	server.Engine.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Contains(t, res.Body.String(), `http_request_duration_seconds_bucket{method="GET",route="/healthz",status="2xx",le="2"} 1`)
+}
+
+func TestServerMetricsBucketsConflict(t *testing.T) {
+	registry := prometheus.NewRegistry()
+
+	api.New(api.WithMetricsRegisterer(registry), api.WithMetricsBuckets(1))
+	server := api.New(api.WithMetricsRegisterer(registry), api.WithMetricsBuckets(2))
+
+	err := server.Run(context.Background())
+	assert.Equal(t, api.ErrMetricsBuckets, err)
+}
+
 func TestNoRoute(t *testing.T) {
 	controller := new(testController)
 
@@ -117,7 +199,7 @@ func TestHostBindingError(t *testing.T) {
 		api.WithController(controller),
 	)
 
-	err := server.Run()
+	err := server.Run(context.Background())
 	assert.Error(t, err)
 }
 
@@ -137,7 +219,7 @@ func TestGracefulShutdown(t *testing.T) {
 	errCh := make(chan error, 1)
 
 	go func() {
-		errCh <- server.Run()
+		errCh <- server.Run(context.Background())
 	}()
 
 	server.Shutdown <- os.Interrupt
@@ -154,3 +236,159 @@ func TestGracefulShutdown(t *testing.T) {
 		t.Fatal("graceful shutdown failed")
 	}
 }
+
+func TestContextShutdown(t *testing.T) {
+	server := api.New(
+		api.WithConfig(&api.Config{
+			Host:            freeAddress(t),
+			ShutdownTimeout: time.Second,
+		}),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+
+	go func() {
+		errCh <- server.Run(ctx)
+	}()
+
+	cancel()
+
+	select {
+	case err := <-errCh:
+		assert.NoError(t, err)
+	case <-time.After(10 * time.Second):
+		t.Fatal("context shutdown failed")
+	}
+}
+
+func TestServerH2C(t *testing.T) {
+	host := freeAddress(t)
+
+	server := api.New(
+		api.WithConfig(&api.Config{
+			Host: host,
+			H2C:  true,
+		}),
+	)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go server.Run(ctx)
+
+	client := &http.Client{
+		Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+				return net.Dial(network, addr)
+			},
+		},
+	}
+
+	var res *http.Response
+	var err error
+
+	for i := 0; i < 10; i++ {
+		if res, err = client.Get(fmt.Sprintf("http://%s/healthz", host)); err == nil {
+			break
+		}
+
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer res.Body.Close()
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, 2, res.ProtoMajor)
+}
+
+func TestServerInvalidTLS(t *testing.T) {
+	server := api.New(
+		api.WithConfig(&api.Config{
+			Host: freeAddress(t),
+			TLS: &api.TLSConfig{
+				CertFile: "invalid.crt",
+				KeyFile:  "invalid.key",
+			},
+		}),
+	)
+
+	assert.Error(t, server.Run(context.Background()))
+}
+
+func freeAddress(t *testing.T) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer ln.Close()
+
+	return ln.Addr().String()
+}
+
+func TestServerLivez(t *testing.T) {
+	server := api.New()
+
+	ts := httptest.NewServer(server.Engine)
+	defer ts.Close()
+
+	res, err := http.Get(fmt.Sprintf("%s/livez", ts.URL))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer res.Body.Close()
+
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+}
+
+func TestReadinessDraining(t *testing.T) {
+	host := freeAddress(t)
+
+	server := api.New(
+		api.WithConfig(&api.Config{
+			Host:         host,
+			PreStopDelay: time.Second,
+		}),
+	)
+
+	errCh := make(chan error, 1)
+
+	go func() {
+		errCh <- server.Run(context.Background())
+	}()
+
+	readyz := func() int {
+		res, err := http.Get(fmt.Sprintf("http://%s/readyz", host))
+
+		if err != nil {
+			return 0
+		}
+
+		res.Body.Close()
+		return res.StatusCode
+	}
+
+	for i := 0; i < 10 && readyz() != http.StatusOK; i++ {
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	assert.True(t, server.Ready())
+
+	server.Shutdown <- os.Interrupt
+
+	for i := 0; i < 10 && server.Ready(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	assert.Equal(t, http.StatusServiceUnavailable, readyz())
+	assert.NoError(t, <-errCh)
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LastEventIDHeader ...
+var LastEventIDHeader = "Last-Event-ID"
+
+// TopicFunc returns the hub topics a request subscribes to
+type TopicFunc func(*gin.Context) []string
+
+// Topics subscribes every request to the given topics
+func Topics(topics ...string) TopicFunc {
+	return func(*gin.Context) []string {
+		return topics
+	}
+}
+
+// SSE streams the hub messages as server-sent events until the client disconnects.
+// Clients resume through the Last-Event-ID header or the last_event_id query param.
+// Keep Config.WriteTimeout at zero, or longer than the streams, for these routes.
+func SSE(hub *Hub, topics TopicFunc) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		lastEventID := ctx.GetHeader(LastEventIDHeader)
+
+		if lastEventID == "" {
+			lastEventID = ctx.Query("last_event_id")
+		}
+
+		client := hub.Subscribe(lastEventID, topics(ctx)...)
+		defer hub.Unsubscribe(client)
+
+		header := ctx.Writer.Header()
+		header.Set("Content-Type", "text/event-stream")
+		header.Set("Cache-Control", "no-cache")
+		header.Set("Connection", "keep-alive")
+		header.Set("X-Accel-Buffering", "no")
+
+		ctx.Status(http.StatusOK)
+		ctx.Writer.Flush()
+
+		heartbeat, stop := hub.heartbeat()
+		defer stop()
+
+		for {
+			select {
+			case <-ctx.Request.Context().Done():
+				return
+			case <-heartbeat:
+				if _, err := ctx.Writer.WriteString(": heartbeat\n\n"); err != nil {
+					return
+				}
+			case message, ok := <-client.Messages():
+				if !ok {
+					return
+				}
+
+				if _, err := ctx.Writer.Write(encodeEvent(message)); err != nil {
+					return
+				}
+			}
+
+			ctx.Writer.Flush()
+		}
+	}
+}
+
+func encodeEvent(message *Message) []byte {
+	buffer := new(bytes.Buffer)
+
+	fmt.Fprintf(buffer, "id: %s\n", message.ID)
+
+	if message.Event != "" {
+		fmt.Fprintf(buffer, "event: %s\n", message.Event)
+	}
+
+	for _, line := range bytes.Split(message.Data, []byte("\n")) {
+		fmt.Fprintf(buffer, "data: %s\n", line)
+	}
+
+	buffer.WriteString("\n")
+
+	return buffer.Bytes()
+}
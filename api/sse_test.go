@@ -0,0 +1,114 @@
+package api_test
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raafvargas/wrapit/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func sseServer(hub *api.Hub) *httptest.Server {
+	engine := gin.New()
+	engine.GET("events", api.SSE(hub, api.Topics("orders")))
+
+	return httptest.NewServer(engine)
+}
+
+func waitClients(hub *api.Hub, clients int) {
+	for i := 0; i < 100 && hub.Clients() != clients; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestSSE(t *testing.T) {
+	hub := api.NewHub()
+	server := sseServer(hub)
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/events")
+	assert.NoError(t, err)
+	defer res.Body.Close()
+
+	assert.Equal(t, "text/event-stream", res.Header.Get("Content-Type"))
+
+	waitClients(hub, 1)
+	hub.Publish("orders", "created", map[string]string{"id": "1"})
+
+	reader := bufio.NewReader(res.Body)
+	lines := []string{}
+
+	for len(lines) < 3 {
+		line, err := reader.ReadString('\n')
+		assert.NoError(t, err)
+		lines = append(lines, strings.TrimSpace(line))
+	}
+
+	assert.True(t, strings.HasPrefix(lines[0], "id: ") && strings.HasSuffix(lines[0], "-1"))
+	assert.Equal(t, []string{"event: created", `data: {"id":"1"}`}, lines[1:])
+
+	res.Body.Close()
+	waitClients(hub, 0)
+	assert.Equal(t, 0, hub.Clients())
+}
+
+func TestSSEResume(t *testing.T) {
+	hub := api.NewHub()
+	all := hub.Subscribe("")
+	hub.Publish("orders", "", 1)
+	hub.Publish("orders", "", 2)
+	hub.Close()
+
+	first, second := <-all.Messages(), <-all.Messages()
+
+	server := sseServer(hub)
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/events", nil)
+	req.Header.Set(api.LastEventIDHeader, first.ID)
+
+	res, err := http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	defer res.Body.Close()
+
+	body := new(strings.Builder)
+	_, err = bufio.NewReader(res.Body).WriteTo(body)
+	assert.NoError(t, err)
+	assert.Equal(t, "id: "+second.ID+"\ndata: 2\n\n", body.String())
+}
+
+func TestSSEHeartbeatDisabled(t *testing.T) {
+	hub := api.NewHub(api.WithHubHeartbeat(0))
+	server := sseServer(hub)
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/events")
+	assert.NoError(t, err)
+	defer res.Body.Close()
+
+	waitClients(hub, 1)
+	hub.Publish("orders", "", 1)
+
+	line, err := bufio.NewReader(res.Body).ReadString('\n')
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(line, "id: "))
+}
+
+func TestSSEHeartbeat(t *testing.T) {
+	hub := api.NewHub(api.WithHubHeartbeat(10 * time.Millisecond))
+	server := sseServer(hub)
+	defer server.Close()
+
+	res, err := http.Get(server.URL + "/events")
+	assert.NoError(t, err)
+	defer res.Body.Close()
+
+	line, err := bufio.NewReader(res.Body).ReadString('\n')
+	assert.NoError(t, err)
+	assert.Equal(t, ": heartbeat\n", line)
+}
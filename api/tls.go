@@ -0,0 +1,120 @@
+package api
+
+import (
+	"crypto/tls"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// DefaultTLSReloadInterval ...
+var DefaultTLSReloadInterval = 10 * time.Second
+
+// TLSConfig ...
+type TLSConfig struct {
+	CertFile       string        `yaml:"cert_file"`
+	KeyFile        string        `yaml:"key_file"`
+	ReloadInterval time.Duration `yaml:"reload_interval"`
+}
+
+// Enabled ...
+func (c *TLSConfig) Enabled() bool {
+	return c != nil && c.CertFile != "" && c.KeyFile != ""
+}
+
+// CertificateReloader loads the certificate pair and reloads it when the files change
+type CertificateReloader struct {
+	certFile string
+	keyFile  string
+	interval time.Duration
+
+	mutex       *sync.Mutex
+	certificate *tls.Certificate
+	modTime     time.Time
+	checkedAt   time.Time
+}
+
+// NewCertificateReloader ...
+func NewCertificateReloader(config *TLSConfig) (*CertificateReloader, error) {
+	reloader := &CertificateReloader{
+		certFile: config.CertFile,
+		keyFile:  config.KeyFile,
+		interval: config.ReloadInterval,
+		mutex:    new(sync.Mutex),
+	}
+
+	if reloader.interval == 0 {
+		reloader.interval = DefaultTLSReloadInterval
+	}
+
+	if err := reloader.load(); err != nil {
+		return nil, err
+	}
+
+	return reloader, nil
+}
+
+// GetCertificate is meant to be used as tls.Config.GetCertificate
+func (r *CertificateReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if time.Since(r.checkedAt) < r.interval {
+		return r.certificate, nil
+	}
+
+	r.checkedAt = time.Now()
+
+	modTime, err := r.lastModified()
+
+	if err != nil || !modTime.After(r.modTime) {
+		return r.certificate, nil
+	}
+
+	if err := r.load(); err != nil {
+		logrus.WithError(err).
+			Error("couldn't reload tls certificate, keeping the current one")
+	}
+
+	return r.certificate, nil
+}
+
+func (r *CertificateReloader) load() error {
+	modTime, err := r.lastModified()
+
+	if err != nil {
+		return err
+	}
+
+	certificate, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+
+	if err != nil {
+		return err
+	}
+
+	r.certificate = &certificate
+	r.modTime = modTime
+	r.checkedAt = time.Now()
+
+	return nil
+}
+
+func (r *CertificateReloader) lastModified() (time.Time, error) {
+	latest := time.Time{}
+
+	for _, file := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(file)
+
+		if err != nil {
+			return latest, err
+		}
+
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+
+	return latest, nil
+}
@@ -0,0 +1,95 @@
+package api_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/raafvargas/wrapit/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCertificateReloader(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tls")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer os.RemoveAll(dir)
+
+	config := &api.TLSConfig{
+		CertFile:       filepath.Join(dir, "tls.crt"),
+		KeyFile:        filepath.Join(dir, "tls.key"),
+		ReloadInterval: time.Nanosecond,
+	}
+
+	writeCertificate(t, config, "first")
+
+	reloader, err := api.NewCertificateReloader(config)
+	assert.NoError(t, err)
+
+	certificate, err := reloader.GetCertificate(nil)
+	assert.NoError(t, err)
+
+	first, _ := x509.ParseCertificate(certificate.Certificate[0])
+	assert.Equal(t, "first", first.Subject.CommonName)
+
+	future := time.Now().Add(time.Minute)
+	writeCertificate(t, config, "second")
+	os.Chtimes(config.CertFile, future, future)
+
+	certificate, err = reloader.GetCertificate(nil)
+	assert.NoError(t, err)
+
+	second, _ := x509.ParseCertificate(certificate.Certificate[0])
+	assert.Equal(t, "second", second.Subject.CommonName)
+}
+
+func TestCertificateReloaderInvalid(t *testing.T) {
+	_, err := api.NewCertificateReloader(&api.TLSConfig{
+		CertFile: "invalid.crt",
+		KeyFile:  "invalid.key",
+	})
+
+	assert.Error(t, err)
+}
+
+func writeCertificate(t *testing.T, config *api.TLSConfig, commonName string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keyDer, err := x509.MarshalECPrivateKey(key)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ioutil.WriteFile(config.CertFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	ioutil.WriteFile(config.KeyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600)
+}
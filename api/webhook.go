@@ -0,0 +1,300 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raafvargas/wrapit/contract"
+)
+
+var (
+	// WebhookBodyKey is the gin context key holding the verified body
+	WebhookBodyKey = "webhook_body"
+
+	// DefaultWebhookTolerance ...
+	DefaultWebhookTolerance = 5 * time.Minute
+
+	// DefaultWebhookMaxBodyBytes ...
+	DefaultWebhookMaxBodyBytes int64 = 1 << 20
+
+	// ErrWebhookSignature ...
+	ErrWebhookSignature = contract.NewError(http.StatusUnauthorized, "invalid webhook signature")
+
+	// ErrWebhookTimestamp ...
+	ErrWebhookTimestamp = contract.NewError(http.StatusUnauthorized, "webhook timestamp outside the tolerance")
+
+	// ErrWebhookNotJSON is returned forwarding bodies that aren't JSON
+	ErrWebhookNotJSON = contract.NewError(http.StatusUnsupportedMediaType, "webhook body must be JSON")
+
+	webhookAlgorithms = map[string]func() hash.Hash{
+		"sha1":   sha1.New,
+		"sha256": sha256.New,
+		"sha512": sha512.New,
+	}
+)
+
+// WebhookConfig describes how a provider signs its webhooks.
+// With SignTimestamp the signed payload is the timestamp, a dot and the body, otherwise the body.
+// TimestampHeader and SignTimestamp require each other, an unsigned timestamp doesn't protect
+// against replays.
+type WebhookConfig struct {
+	SignatureHeader string        `yaml:"signature_header"`
+	SignaturePrefix string        `yaml:"signature_prefix"`
+	Algorithm       string        `yaml:"algorithm"`
+	Encoding        string        `yaml:"encoding"`
+	Secrets         []string      `yaml:"secrets"`
+	TimestampHeader string        `yaml:"timestamp_header"`
+	SignTimestamp   bool          `yaml:"sign_timestamp"`
+	Tolerance       time.Duration `yaml:"tolerance"`
+	DeliveryHeader  string        `yaml:"delivery_header"`
+	DeliveryTTL     time.Duration `yaml:"delivery_ttl"`
+	EventHeader     string        `yaml:"event_header"`
+	MaxBodyBytes    int64         `yaml:"max_body_bytes"`
+}
+
+// WebhookPublisher forwards verified webhooks, such as rabbitmq.Producer.
+// The event type is set in the publish context through contract.ContextWithEventType.
+type WebhookPublisher interface {
+	Publish(ctx context.Context, exchange string, message interface{}) error
+}
+
+// WebhookVerifier verifies webhook signatures against every active secret, so secrets
+// can be rotated, and rejects deliveries outside the timestamp tolerance
+type WebhookVerifier struct {
+	config *WebhookConfig
+	hash   func() hash.Hash
+	store  contract.IdempotencyStore
+}
+
+// NewWebhookVerifier creates the verifier. Deliveries are deduplicated by the delivery
+// header through the store, which may be nil to disable deduplication.
+func NewWebhookVerifier(config *WebhookConfig, store contract.IdempotencyStore) (*WebhookVerifier, error) {
+	algorithm := strings.ToLower(config.Algorithm)
+
+	if algorithm == "" {
+		algorithm = "sha256"
+	}
+
+	h, ok := webhookAlgorithms[algorithm]
+
+	if !ok {
+		return nil, fmt.Errorf("unsupported webhook algorithm %s", config.Algorithm)
+	}
+
+	if config.SignatureHeader == "" || len(config.Secrets) == 0 {
+		return nil, fmt.Errorf("webhook signature header and secrets must not be empty")
+	}
+
+	if config.Encoding != "" && config.Encoding != "hex" && config.Encoding != "base64" {
+		return nil, fmt.Errorf("unsupported webhook encoding %s", config.Encoding)
+	}
+
+	if config.TimestampHeader != "" && !config.SignTimestamp {
+		return nil, fmt.Errorf("webhook timestamp header requires sign timestamp")
+	}
+
+	if config.SignTimestamp && config.TimestampHeader == "" {
+		return nil, fmt.Errorf("webhook sign timestamp requires a timestamp header")
+	}
+
+	return &WebhookVerifier{
+		config: config,
+		hash:   h,
+		store:  store,
+	}, nil
+}
+
+// Verify checks the timestamp and signature headers against the body
+func (v *WebhookVerifier) Verify(header http.Header, body []byte) error {
+	timestamp := ""
+
+	if v.config.TimestampHeader != "" {
+		timestamp = header.Get(v.config.TimestampHeader)
+
+		if err := v.verifyTimestamp(timestamp); err != nil {
+			return err
+		}
+	}
+
+	payload := body
+
+	if v.config.SignTimestamp {
+		payload = append([]byte(timestamp+"."), body...)
+	}
+
+	for _, secret := range v.config.Secrets {
+		mac := hmac.New(v.hash, []byte(secret))
+		mac.Write(payload)
+		expected := mac.Sum(nil)
+
+		for _, signature := range v.signatures(header.Get(v.config.SignatureHeader)) {
+			if hmac.Equal(signature, expected) {
+				return nil
+			}
+		}
+	}
+
+	return ErrWebhookSignature
+}
+
+// Handler verifies the request and stores the body under WebhookBodyKey. Repeated
+// deliveries replay the first response instead of reaching the next handlers.
+func (v *WebhookVerifier) Handler() gin.HandlerFunc {
+	ttl := v.config.DeliveryTTL
+
+	if ttl == 0 {
+		ttl = DefaultIdempotencyTTL
+	}
+
+	limit := v.config.MaxBodyBytes
+
+	if limit == 0 {
+		limit = DefaultWebhookMaxBodyBytes
+	}
+
+	return func(ctx *gin.Context) {
+		body, err := ioutil.ReadAll(limitBody(ctx.Request.Body, limit))
+
+		if err != nil {
+			BindingError(ctx, err)
+			return
+		}
+
+		ctx.Request.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		if err := v.Verify(ctx.Request.Header, body); err != nil {
+			ResolveError(ctx, err)
+			return
+		}
+
+		ctx.Set(WebhookBodyKey, body)
+
+		delivery := ""
+
+		if v.config.DeliveryHeader != "" {
+			delivery = ctx.GetHeader(v.config.DeliveryHeader)
+		}
+
+		if v.store == nil || delivery == "" {
+			ctx.Next()
+			return
+		}
+
+		sum := sha256.Sum256(body)
+
+		serveIdempotent(ctx, v.store, &contract.IdempotencyRecord{
+			Key:         "webhook:" + ctx.Request.URL.Path + ":" + delivery,
+			Fingerprint: hex.EncodeToString(sum[:]),
+			ExpiresAt:   time.Now().Add(ttl),
+		})
+	}
+}
+
+// Forward publishes the verified JSON body to the exchange and responds 202, or 415 for
+// other bodies. The event header, when configured, is used as the event type.
+func (v *WebhookVerifier) Forward(publisher WebhookPublisher, exchange string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		body := GetWebhookBody(ctx)
+
+		if !json.Valid(body) {
+			ResolveError(ctx, ErrWebhookNotJSON)
+			return
+		}
+
+		publishCtx := ctx.Request.Context()
+
+		if v.config.EventHeader != "" {
+			if event := ctx.GetHeader(v.config.EventHeader); event != "" {
+				publishCtx = contract.ContextWithEventType(publishCtx, event)
+			}
+		}
+
+		if err := publisher.Publish(publishCtx, exchange, json.RawMessage(body)); err != nil {
+			ResolveError(ctx, err)
+			return
+		}
+
+		ctx.Status(http.StatusAccepted)
+	}
+}
+
+// GetWebhookBody returns the body verified by the webhook handler
+func GetWebhookBody(ctx *gin.Context) []byte {
+	body, _ := ctx.Get(WebhookBodyKey)
+	data, _ := body.([]byte)
+	return data
+}
+
+func (v *WebhookVerifier) verifyTimestamp(value string) error {
+	tolerance := v.config.Tolerance
+
+	if tolerance == 0 {
+		tolerance = DefaultWebhookTolerance
+	}
+
+	timestamp, err := parseWebhookTimestamp(value)
+
+	if err != nil {
+		return ErrWebhookTimestamp
+	}
+
+	if diff := time.Since(timestamp); diff > tolerance || diff < -tolerance {
+		return ErrWebhookTimestamp
+	}
+
+	return nil
+}
+
+// signatures decodes the comma or space separated signatures of the header
+func (v *WebhookVerifier) signatures(header string) [][]byte {
+	signatures := [][]byte{}
+
+	for _, value := range strings.FieldsFunc(header, func(r rune) bool { return r == ',' || r == ' ' }) {
+		if v.config.SignaturePrefix != "" {
+			if !strings.HasPrefix(value, v.config.SignaturePrefix) {
+				continue
+			}
+
+			value = strings.TrimPrefix(value, v.config.SignaturePrefix)
+		}
+
+		var signature []byte
+		var err error
+
+		if v.config.Encoding == "base64" {
+			signature, err = base64.StdEncoding.DecodeString(value)
+		} else {
+			signature, err = hex.DecodeString(value)
+		}
+
+		if err == nil {
+			signatures = append(signatures, signature)
+		}
+	}
+
+	return signatures
+}
+
+// parseWebhookTimestamp accepts unix seconds or RFC 3339
+func parseWebhookTimestamp(value string) (time.Time, error) {
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0), nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
@@ -0,0 +1,212 @@
+package api_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raafvargas/wrapit/api"
+	"github.com/raafvargas/wrapit/contract"
+	"github.com/raafvargas/wrapit/rabbitmq"
+	"github.com/stretchr/testify/assert"
+)
+
+type publisherMock struct {
+	exchange  string
+	eventType string
+	message   interface{}
+	err       error
+}
+
+func (p *publisherMock) Publish(ctx context.Context, exchange string, message interface{}) error {
+	p.exchange, p.message = exchange, message
+	p.eventType, _ = contract.EventTypeFromContext(ctx)
+	return p.err
+}
+
+func sign(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func webhookConfig() *api.WebhookConfig {
+	return &api.WebhookConfig{
+		SignatureHeader: "X-Signature",
+		SignaturePrefix: "sha256=",
+		Secrets:         []string{"new", "old"},
+		TimestampHeader: "X-Timestamp",
+		SignTimestamp:   true,
+		DeliveryHeader:  "X-Delivery",
+		EventHeader:     "X-Event",
+	}
+}
+
+func webhookRequest(engine *gin.Engine, body, signature, timestamp, delivery string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader(body))
+	req.Header.Set("X-Signature", signature)
+	req.Header.Set("X-Timestamp", timestamp)
+	req.Header.Set("X-Delivery", delivery)
+	req.Header.Set("X-Event", "push")
+
+	res := httptest.NewRecorder()
+	engine.ServeHTTP(res, req)
+
+	return res
+}
+
+func webhookEngine(t *testing.T, publisher api.WebhookPublisher) *gin.Engine {
+	verifier, err := api.NewWebhookVerifier(webhookConfig(), api.NewMemoryIdempotencyStore())
+	assert.NoError(t, err)
+
+	engine := gin.New()
+	engine.POST("webhook", verifier.Handler(), verifier.Forward(publisher, "webhooks"))
+
+	return engine
+}
+
+func TestWebhook(t *testing.T) {
+	publisher := new(publisherMock)
+	engine := webhookEngine(t, publisher)
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	body := `{"id":1}`
+
+	res := webhookRequest(engine, body, sign("old", timestamp+"."+body), timestamp, "1")
+
+	assert.Equal(t, http.StatusAccepted, res.Code)
+	assert.Equal(t, "webhooks", publisher.exchange)
+	assert.Equal(t, "push", publisher.eventType)
+	assert.JSONEq(t, body, string(publisher.message.(json.RawMessage)))
+}
+
+func TestWebhookNotJSON(t *testing.T) {
+	publisher := new(publisherMock)
+	engine := webhookEngine(t, publisher)
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	body := "id=1"
+
+	res := webhookRequest(engine, body, sign("new", timestamp+"."+body), timestamp, "1")
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, res.Code)
+	assert.Empty(t, publisher.exchange)
+}
+
+func TestWebhookBodyTooLarge(t *testing.T) {
+	config := webhookConfig()
+	config.MaxBodyBytes = 4
+
+	verifier, err := api.NewWebhookVerifier(config, nil)
+	assert.NoError(t, err)
+
+	engine := gin.New()
+	engine.POST("webhook", verifier.Handler(), verifier.Forward(new(publisherMock), "webhooks"))
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	body := `{"id":1}`
+
+	res := webhookRequest(engine, body, sign("new", timestamp+"."+body), timestamp, "1")
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, res.Code)
+}
+
+func TestWebhookInvalidSignature(t *testing.T) {
+	engine := webhookEngine(t, new(publisherMock))
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	res := webhookRequest(engine, `{"id":1}`, sign("other", timestamp+`.{"id":1}`), timestamp, "1")
+	assert.Equal(t, http.StatusUnauthorized, res.Code)
+
+	res = webhookRequest(engine, `{"id":1}`, "", timestamp, "1")
+	assert.Equal(t, http.StatusUnauthorized, res.Code)
+}
+
+func TestWebhookStaleTimestamp(t *testing.T) {
+	engine := webhookEngine(t, new(publisherMock))
+	timestamp := strconv.FormatInt(time.Now().Add(-time.Hour).Unix(), 10)
+
+	res := webhookRequest(engine, `{"id":1}`, sign("new", timestamp+`.{"id":1}`), timestamp, "1")
+	assert.Equal(t, http.StatusUnauthorized, res.Code)
+}
+
+func TestWebhookDuplicateDelivery(t *testing.T) {
+	publisher := new(publisherMock)
+	engine := webhookEngine(t, publisher)
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	body := `{"id":1}`
+	signature := sign("new", timestamp+"."+body)
+
+	assert.Equal(t, http.StatusAccepted, webhookRequest(engine, body, signature, timestamp, "1").Code)
+
+	publisher.exchange = ""
+	res := webhookRequest(engine, body, signature, timestamp, "1")
+
+	assert.Equal(t, http.StatusAccepted, res.Code)
+	assert.Equal(t, "true", res.Header().Get(api.IdempotentReplayedHeader))
+	assert.Empty(t, publisher.exchange)
+}
+
+func TestWebhookPublishFailure(t *testing.T) {
+	publisher := &publisherMock{err: errors.New("connection closed")}
+	engine := webhookEngine(t, publisher)
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	body := `{"id":1}`
+	signature := sign("new", timestamp+"."+body)
+
+	assert.Equal(t, http.StatusInternalServerError, webhookRequest(engine, body, signature, timestamp, "1").Code)
+
+	publisher.err = nil
+	assert.Equal(t, http.StatusAccepted, webhookRequest(engine, body, signature, timestamp, "1").Code)
+}
+
+func TestNewWebhookVerifierInvalid(t *testing.T) {
+	_, err := api.NewWebhookVerifier(&api.WebhookConfig{SignatureHeader: "X", Secrets: []string{"s"}, Algorithm: "md5"}, nil)
+	assert.Error(t, err)
+
+	_, err = api.NewWebhookVerifier(&api.WebhookConfig{Secrets: []string{"s"}}, nil)
+	assert.Error(t, err)
+
+	_, err = api.NewWebhookVerifier(&api.WebhookConfig{
+		SignatureHeader: "X",
+		Secrets:         []string{"s"},
+		TimestampHeader: "X-Timestamp",
+	}, nil)
+	assert.Error(t, err)
+
+	_, err = api.NewWebhookVerifier(&api.WebhookConfig{
+		SignatureHeader: "X",
+		Secrets:         []string{"s"},
+		SignTimestamp:   true,
+	}, nil)
+	assert.Error(t, err)
+}
+
+func TestWebhookVerifyBodyOnly(t *testing.T) {
+	verifier, err := api.NewWebhookVerifier(&api.WebhookConfig{
+		SignatureHeader: "X-Hub-Signature-256",
+		SignaturePrefix: "sha256=",
+		Secrets:         []string{"secret"},
+	}, nil)
+	assert.NoError(t, err)
+
+	header := http.Header{}
+	header.Set("X-Hub-Signature-256", sign("secret", "body"))
+
+	assert.NoError(t, verifier.Verify(header, []byte("body")))
+	assert.Equal(t, api.ErrWebhookSignature, verifier.Verify(header, []byte("other")))
+}
+
+var _ api.WebhookPublisher = (*rabbitmq.Producer)(nil)
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/net/websocket"
+)
+
+// WebSocket pushes the hub messages as JSON text frames until the client disconnects.
+// Incoming frames are discarded. Requests from other origins are rejected unless they
+// match one of the origins, which accept the same wildcards as CORSConfig.AllowOrigins.
+func WebSocket(hub *Hub, topics TopicFunc, origins ...string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		lastEventID := ctx.Query("last_event_id")
+		subscribed := topics(ctx)
+
+		server := websocket.Server{
+			Handshake: func(config *websocket.Config, req *http.Request) error {
+				return checkOrigin(config, req, origins)
+			},
+			Handler: func(conn *websocket.Conn) {
+				client := hub.Subscribe(lastEventID, subscribed...)
+				defer hub.Unsubscribe(client)
+
+				serveWebSocket(conn, client, hub)
+			},
+		}
+
+		server.ServeHTTP(ctx.Writer, ctx.Request)
+	}
+}
+
+func serveWebSocket(conn *websocket.Conn, client *HubClient, hub *Hub) {
+	defer conn.Close()
+
+	disconnected := make(chan struct{})
+
+	go func() {
+		defer close(disconnected)
+
+		var discard []byte
+
+		for {
+			if err := websocket.Message.Receive(conn, &discard); err != nil {
+				return
+			}
+		}
+	}()
+
+	heartbeat, stop := hub.heartbeat()
+	defer stop()
+
+	for {
+		select {
+		case <-disconnected:
+			return
+		case <-heartbeat:
+			if err := ping(conn); err != nil {
+				return
+			}
+		case message, ok := <-client.Messages():
+			if !ok {
+				return
+			}
+
+			if err := websocket.JSON.Send(conn, message); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func ping(conn *websocket.Conn) error {
+	conn.PayloadType = websocket.PingFrame
+	defer func() { conn.PayloadType = websocket.TextFrame }()
+
+	_, err := conn.Write(nil)
+	return err
+}
+
+func checkOrigin(config *websocket.Config, req *http.Request, origins []string) error {
+	origin, err := websocket.Origin(config, req)
+
+	if err != nil {
+		return err
+	}
+
+	if origin == nil || origin.Host == req.Host || allowedOrigin(origins, origin.String()) {
+		return nil
+	}
+
+	return errors.New("websocket origin not allowed")
+}
@@ -0,0 +1,63 @@
+package api_test
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raafvargas/wrapit/api"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/websocket"
+)
+
+func websocketServer(hub *api.Hub) (*httptest.Server, string) {
+	engine := gin.New()
+	engine.GET("ws", api.WebSocket(hub, api.Topics("orders"), "https://*.example.com"))
+
+	server := httptest.NewServer(engine)
+
+	return server, "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+}
+
+func TestWebSocket(t *testing.T) {
+	hub := api.NewHub()
+	server, url := websocketServer(hub)
+	defer server.Close()
+
+	conn, err := websocket.Dial(url, "", server.URL)
+	assert.NoError(t, err)
+	defer conn.Close()
+
+	waitClients(hub, 1)
+	hub.Publish("orders", "created", map[string]string{"id": "1"})
+
+	message := new(api.Message)
+	assert.NoError(t, websocket.JSON.Receive(conn, message))
+	assert.True(t, strings.HasSuffix(message.ID, "-1"))
+	assert.Equal(t, "orders", message.Topic)
+	assert.JSONEq(t, `{"id":"1"}`, string(message.Data))
+
+	conn.Close()
+	waitClients(hub, 0)
+	assert.Equal(t, 0, hub.Clients())
+}
+
+func TestWebSocketAllowedOrigin(t *testing.T) {
+	hub := api.NewHub()
+	server, url := websocketServer(hub)
+	defer server.Close()
+
+	conn, err := websocket.Dial(url, "", "https://app.example.com")
+	assert.NoError(t, err)
+	conn.Close()
+}
+
+func TestWebSocketForbiddenOrigin(t *testing.T) {
+	hub := api.NewHub()
+	server, url := websocketServer(hub)
+	defer server.Close()
+
+	_, err := websocket.Dial(url, "", "https://evil.com")
+	assert.Error(t, err)
+}
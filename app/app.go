@@ -0,0 +1,183 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// DefaultShutdownTimeout bounds the whole shutdown, not each component
+	DefaultShutdownTimeout = 30 * time.Second
+
+	// DefaultSignals ...
+	DefaultSignals = []os.Signal{os.Interrupt, syscall.SIGTERM}
+
+	// ErrShutdownTimeout ...
+	ErrShutdownTimeout = errors.New("shutdown timeout exceeded")
+)
+
+// RunFunc runs a component until ctx is cancelled
+type RunFunc func(ctx context.Context) error
+
+// CloseFunc releases a resource, giving up when ctx is done
+type CloseFunc func(ctx context.Context) error
+
+type component struct {
+	name  string
+	run   RunFunc
+	close CloseFunc
+	done  chan struct{}
+	err   error
+}
+
+// App runs the registered components together and shuts them down in the reverse
+// order they were registered, so dependencies are registered before their dependents.
+type App struct {
+	components []*component
+
+	ShutdownTimeout time.Duration
+	Signals         []os.Signal
+	Logger          *logrus.Logger
+}
+
+// New ...
+func New(options ...Option) *App {
+	app := &App{
+		ShutdownTimeout: DefaultShutdownTimeout,
+		Signals:         DefaultSignals,
+		Logger:          logrus.StandardLogger(),
+	}
+
+	for _, o := range options {
+		o(app)
+	}
+
+	return app
+}
+
+// Run starts the components and blocks until a signal, the cancellation of ctx
+// or a worker returning, then shuts everything down. It returns the errors of
+// the components that failed or didn't stop in time.
+func (a *App) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	signals := make(chan os.Signal, 1)
+
+	if len(a.Signals) > 0 {
+		signal.Notify(signals, a.Signals...)
+		defer signal.Stop(signals)
+	}
+
+	exited := make(chan *component, len(a.components))
+	cancels := make(map[*component]context.CancelFunc)
+
+	for _, c := range a.components {
+		if c.run == nil {
+			continue
+		}
+
+		// components are cancelled one by one on shutdown, not with the parent ctx
+		runCtx, runCancel := context.WithCancel(context.Background())
+		cancels[c] = runCancel
+		c.done = make(chan struct{})
+
+		a.Logger.WithField("component", c.name).Info("starting component")
+
+		go func(c *component) {
+			defer close(c.done)
+
+			c.err = c.run(runCtx)
+			exited <- c
+		}(c)
+	}
+
+	select {
+	case sig := <-signals:
+		a.Logger.WithField("sig", sig.String()).Info("got signal, shutting down")
+		// a second signal terminates the process with the default behavior
+		signal.Stop(signals)
+	case <-ctx.Done():
+		a.Logger.Info("context done, shutting down")
+	case c := <-exited:
+		logger := a.Logger.WithField("component", c.name)
+
+		if c.err != nil {
+			logger.WithError(c.err).Error("component failed, shutting down")
+			break
+		}
+
+		logger.Warn("component stopped early, shutting down")
+	}
+
+	return a.shutdown(cancels)
+}
+
+// Main runs the app and exits with status 1 when it fails
+func (a *App) Main() {
+	if err := a.Run(context.Background()); err != nil {
+		a.Logger.WithError(err).Error("app failed")
+		os.Exit(1)
+	}
+}
+
+func (a *App) shutdown(cancels map[*component]context.CancelFunc) error {
+	ctx, cancel := context.WithTimeout(context.Background(), a.ShutdownTimeout)
+	defer cancel()
+
+	errs := []string{}
+
+	for i := len(a.components) - 1; i >= 0; i-- {
+		c := a.components[i]
+		logger := a.Logger.WithField("component", c.name)
+
+		if err := a.stop(ctx, c, cancels[c]); err != nil {
+			logger.WithError(err).Error("component didn't stop cleanly")
+			errs = append(errs, fmt.Sprintf("%s: %s", c.name, err))
+			continue
+		}
+
+		logger.Info("component stopped")
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// stop cancels a running component or calls its closer, waiting until ctx is done
+func (a *App) stop(ctx context.Context, c *component, cancel context.CancelFunc) error {
+	if c.run != nil {
+		cancel()
+
+		select {
+		case <-c.done:
+			return c.err
+		case <-ctx.Done():
+			return ErrShutdownTimeout
+		}
+	}
+
+	closed := make(chan error, 1)
+
+	go func() {
+		closed <- c.close(ctx)
+	}()
+
+	select {
+	case err := <-closed:
+		return err
+	case <-ctx.Done():
+		return ErrShutdownTimeout
+	}
+}
@@ -0,0 +1,138 @@
+package app_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/raafvargas/wrapit/app"
+	"github.com/stretchr/testify/assert"
+)
+
+type recorder struct {
+	mutex  sync.Mutex
+	events []string
+}
+
+func (r *recorder) record(event string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recorder) worker(name string) app.RunFunc {
+	return func(ctx context.Context) error {
+		<-ctx.Done()
+		r.record("stop " + name)
+		return nil
+	}
+}
+
+func (r *recorder) closer(name string) app.CloseFunc {
+	return func(context.Context) error {
+		r.record("close " + name)
+		return nil
+	}
+}
+
+func TestRunShutdownOrder(t *testing.T) {
+	r := new(recorder)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	a := app.New(
+		app.WithSignals(),
+		app.WithCloser("connection", r.closer("connection")),
+		app.WithWorker("consumer", r.worker("consumer")),
+		app.WithWorker("server", r.worker("server")),
+	)
+
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	assert.NoError(t, a.Run(ctx))
+	assert.Equal(t, []string{"stop server", "stop consumer", "close connection"}, r.events)
+}
+
+func TestRunComponentFailure(t *testing.T) {
+	r := new(recorder)
+
+	a := app.New(
+		app.WithSignals(),
+		app.WithCloser("connection", r.closer("connection")),
+		app.WithWorker("server", r.worker("server")),
+		app.WithWorker("consumer", func(context.Context) error {
+			return errors.New("connection lost")
+		}),
+	)
+
+	err := a.Run(context.Background())
+
+	assert.EqualError(t, err, "consumer: connection lost")
+	assert.Equal(t, []string{"stop server", "close connection"}, r.events)
+}
+
+func TestRunWorkerReturned(t *testing.T) {
+	r := new(recorder)
+
+	a := app.New(
+		app.WithSignals(),
+		app.WithWorker("server", r.worker("server")),
+		app.WithWorker("job", func(context.Context) error {
+			return nil
+		}),
+	)
+
+	assert.NoError(t, a.Run(context.Background()))
+	assert.Equal(t, []string{"stop server"}, r.events)
+}
+
+func TestRunShutdownTimeout(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	a := app.New(
+		app.WithSignals(),
+		app.WithShutdownTimeout(10*time.Millisecond),
+		app.WithWorker("stuck", func(context.Context) error {
+			select {}
+		}),
+	)
+
+	err := a.Run(ctx)
+
+	assert.EqualError(t, err, "stuck: "+app.ErrShutdownTimeout.Error())
+}
+
+func TestRunCloserError(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	a := app.New(
+		app.WithSignals(),
+		app.WithCloser("connection", app.Close(func() error {
+			return errors.New("already closed")
+		})),
+	)
+
+	assert.EqualError(t, a.Run(ctx), "connection: already closed")
+}
+
+func TestRunSignal(t *testing.T) {
+	r := new(recorder)
+
+	a := app.New(
+		app.WithSignals(syscall.SIGUSR1),
+		app.WithWorker("server", r.worker("server")),
+	)
+
+	time.AfterFunc(10*time.Millisecond, func() {
+		p, _ := os.FindProcess(os.Getpid())
+		p.Signal(syscall.SIGUSR1)
+	})
+
+	assert.NoError(t, a.Run(context.Background()))
+	assert.Equal(t, []string{"stop server"}, r.events)
+}
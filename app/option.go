@@ -0,0 +1,100 @@
+package app
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/raafvargas/wrapit/api"
+	"github.com/raafvargas/wrapit/grpc"
+	"github.com/raafvargas/wrapit/rabbitmq"
+	"github.com/sirupsen/logrus"
+)
+
+// Option ...
+type Option func(*App)
+
+// WithShutdownTimeout ...
+func WithShutdownTimeout(timeout time.Duration) Option {
+	return func(a *App) {
+		a.ShutdownTimeout = timeout
+	}
+}
+
+// WithSignals ...
+func WithSignals(signals ...os.Signal) Option {
+	return func(a *App) {
+		a.Signals = signals
+	}
+}
+
+// WithLogger ...
+func WithLogger(logger *logrus.Logger) Option {
+	return func(a *App) {
+		a.Logger = logger
+	}
+}
+
+// WithWorker runs a background worker until the app shuts down.
+// A worker returning before the shutdown, even without an error, shuts the app down.
+func WithWorker(name string, run RunFunc) Option {
+	return func(a *App) {
+		a.components = append(a.components, &component{name: name, run: run})
+	}
+}
+
+// WithCloser calls close when the app shuts down
+func WithCloser(name string, close CloseFunc) Option {
+	return func(a *App) {
+		a.components = append(a.components, &component{name: name, close: close})
+	}
+}
+
+// WithServer runs the HTTP server, leaving the signal handling to the app
+func WithServer(server *api.Server) Option {
+	return func(a *App) {
+		api.WithSignals()(server)
+		WithWorker("http", server.Run)(a)
+	}
+}
+
+// WithGRPCServer runs the gRPC server, leaving the signal handling to the app
+func WithGRPCServer(server *grpc.GRPCServer) Option {
+	return func(a *App) {
+		grpc.WithServerSignals()(server)
+		WithWorker("grpc", server.Run)(a)
+	}
+}
+
+// WithConsumer runs the consumer, leaving the signal handling to the app
+func WithConsumer(consumer *rabbitmq.Consumer) Option {
+	return func(a *App) {
+		rabbitmq.WithSignals()(consumer)
+		WithWorker("consumer "+consumer.Queue, consumer.Consume)(a)
+	}
+}
+
+// WithRabbitConnection closes the connection when the app shuts down
+func WithRabbitConnection(connection *rabbitmq.RabbitConnection) Option {
+	return WithCloser("rabbitmq", Close(connection.Close))
+}
+
+// WithTracingFlush flushes the spans returned by tracing.Register when the app shuts down
+func WithTracingFlush(flush func()) Option {
+	return WithCloser("tracing", Flush(flush))
+}
+
+// Close adapts closers such as io.Closer.Close
+func Close(close func() error) CloseFunc {
+	return func(context.Context) error {
+		return close()
+	}
+}
+
+// Flush adapts closers that can't fail
+func Flush(flush func()) CloseFunc {
+	return func(context.Context) error {
+		flush()
+		return nil
+	}
+}
@@ -0,0 +1,51 @@
+package app_test
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/raafvargas/wrapit/api"
+	"github.com/raafvargas/wrapit/app"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithShutdownTimeout(t *testing.T) {
+	a := app.New(app.WithShutdownTimeout(time.Second))
+	assert.Equal(t, time.Second, a.ShutdownTimeout)
+}
+
+func TestWithSignals(t *testing.T) {
+	a := app.New(app.WithSignals(os.Interrupt))
+	assert.Equal(t, []os.Signal{os.Interrupt}, a.Signals)
+}
+
+func TestWithLogger(t *testing.T) {
+	logger := logrus.New()
+	a := app.New(app.WithLogger(logger))
+	assert.Equal(t, logger, a.Logger)
+}
+
+func TestWithServer(t *testing.T) {
+	server := api.New(api.WithHost("127.0.0.1:0"))
+
+	option := app.WithServer(server)
+	assert.NotEmpty(t, server.Signals)
+
+	a := app.New(app.WithSignals(), option)
+	assert.Empty(t, server.Signals)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	assert.NoError(t, a.Run(ctx))
+}
+
+func TestFlush(t *testing.T) {
+	flushed := false
+
+	assert.NoError(t, app.Flush(func() { flushed = true })(context.Background()))
+	assert.True(t, flushed)
+}
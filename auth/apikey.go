@@ -0,0 +1,296 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raafvargas/wrapit/contract"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// DefaultAPIKeyHeader ...
+	DefaultAPIKeyHeader = "X-API-Key"
+
+	// DefaultAPIKeyLastUsedInterval limits the last used writes of each key
+	DefaultAPIKeyLastUsedInterval = time.Minute
+
+	// APIKeySeparator splits the identifying prefix from the secret of a key
+	APIKeySeparator = "."
+
+	// APIKeyClaim holds the prefix of the key authenticating the principal
+	APIKeyClaim = "api_key"
+
+	// ErrMissingAPIKey ...
+	ErrMissingAPIKey = errors.New("missing api key")
+
+	// ErrInvalidAPIKey ...
+	ErrInvalidAPIKey = errors.New("invalid api key")
+
+	// ErrExpiredAPIKey ...
+	ErrExpiredAPIKey = errors.New("expired api key")
+)
+
+// StaticAPIKey is an API key of the configuration. Key holds the plain key for
+// development, otherwise Prefix and Hash are required.
+type StaticAPIKey struct {
+	contract.APIKey `yaml:",inline"`
+	Key             string `yaml:"key"`
+}
+
+// APIKeyConfig ...
+type APIKeyConfig struct {
+	Header           string         `yaml:"header"`
+	Query            string         `yaml:"query"`
+	LastUsedInterval time.Duration  `yaml:"last_used_interval"`
+	Keys             []StaticAPIKey `yaml:"keys"`
+}
+
+// APIKeyHandler authenticates requests with API keys read from a header or query param
+type APIKeyHandler struct {
+	store            contract.APIKeyStore
+	header           string
+	query            string
+	lastUsedInterval time.Duration
+
+	mutex    *sync.Mutex
+	touching map[string]bool
+}
+
+// NewAPIKeyHandler creates the handler using the static keys of the config when store is nil
+func NewAPIKeyHandler(config *APIKeyConfig, store contract.APIKeyStore) *APIKeyHandler {
+	handler := &APIKeyHandler{
+		store:            store,
+		header:           config.Header,
+		query:            config.Query,
+		lastUsedInterval: config.LastUsedInterval,
+		mutex:            new(sync.Mutex),
+		touching:         make(map[string]bool),
+	}
+
+	if handler.store == nil {
+		handler.store = NewStaticAPIKeyStore(config.Keys...)
+	}
+
+	if handler.header == "" {
+		handler.header = DefaultAPIKeyHeader
+	}
+
+	if handler.lastUsedInterval == 0 {
+		handler.lastUsedInterval = DefaultAPIKeyLastUsedInterval
+	}
+
+	return handler
+}
+
+// HTTP ...
+func (h *APIKeyHandler) HTTP() gin.HandlerFunc {
+	return authenticateHTTP(h)
+}
+
+// Authenticate ...
+func (h *APIKeyHandler) Authenticate(req *http.Request) (*Principal, error) {
+	raw := req.Header.Get(h.header)
+
+	if raw == "" && h.query != "" {
+		raw = req.URL.Query().Get(h.query)
+	}
+
+	if raw == "" {
+		return nil, ErrMissingAPIKey
+	}
+
+	key, err := h.Validate(req.Context(), raw)
+
+	if err != nil {
+		return nil, err
+	}
+
+	claims := map[string]interface{}{
+		"sub":       key.Subject,
+		"scope":     strings.Join(key.Scopes, " "),
+		APIKeyClaim: key.Prefix,
+	}
+
+	if key.Tenant != "" {
+		claims[TenantClaim] = key.Tenant
+	}
+
+	if !key.ExpiresAt.IsZero() {
+		claims["exp"] = float64(key.ExpiresAt.Unix())
+	}
+
+	return NewPrincipal(claims), nil
+}
+
+// Validate looks up the key by prefix, compares its hash and checks the expiration
+func (h *APIKeyHandler) Validate(ctx context.Context, raw string) (*contract.APIKey, error) {
+	i := strings.LastIndex(raw, APIKeySeparator)
+
+	if i <= 0 {
+		return nil, ErrInvalidAPIKey
+	}
+
+	key, err := h.store.Find(ctx, raw[:i])
+
+	if err != nil {
+		return nil, err
+	}
+
+	if key == nil {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if subtle.ConstantTimeCompare([]byte(HashAPIKey(raw)), []byte(key.Hash)) != 1 {
+		return nil, ErrInvalidAPIKey
+	}
+
+	now := time.Now()
+
+	if !key.ExpiresAt.IsZero() && now.After(key.ExpiresAt) {
+		return nil, ErrExpiredAPIKey
+	}
+
+	if now.Sub(key.LastUsedAt) >= h.lastUsedInterval && h.startTouch(key.ID) {
+		go h.touch(key.ID, now)
+	}
+
+	return key, nil
+}
+
+// startTouch allows a single last used write in flight per key
+func (h *APIKeyHandler) startTouch(id string) bool {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	if h.touching[id] {
+		return false
+	}
+
+	h.touching[id] = true
+
+	return true
+}
+
+func (h *APIKeyHandler) touch(id string, at time.Time) {
+	defer func() {
+		h.mutex.Lock()
+		delete(h.touching, id)
+		h.mutex.Unlock()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := h.store.Touch(ctx, id, at); err != nil {
+		logrus.WithError(err).WithField("api_key", id).Warn("couldn't track api key usage")
+	}
+}
+
+// GenerateAPIKey creates a key with the given prefix, e.g. "live", returning the plain
+// key to hand out once and the record to store
+func GenerateAPIKey(prefix, subject string, scopes ...string) (string, *contract.APIKey, error) {
+	id := make([]byte, 6)
+	secret := make([]byte, 32)
+
+	if _, err := rand.Read(id); err != nil {
+		return "", nil, err
+	}
+
+	if _, err := rand.Read(secret); err != nil {
+		return "", nil, err
+	}
+
+	key := &contract.APIKey{
+		ID:      hex.EncodeToString(id),
+		Prefix:  prefix + "_" + hex.EncodeToString(id),
+		Subject: subject,
+		Scopes:  scopes,
+	}
+
+	raw := key.Prefix + APIKeySeparator + base64.RawURLEncoding.EncodeToString(secret)
+	key.Hash = HashAPIKey(raw)
+
+	return raw, key, nil
+}
+
+// HashAPIKey returns the hex SHA-256 of the key. API keys are random enough to not need
+// a slow password hash.
+func HashAPIKey(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
+
+// StaticAPIKeyStore keeps the keys in memory
+type StaticAPIKeyStore struct {
+	mutex *sync.RWMutex
+	keys  map[string]*contract.APIKey
+}
+
+// NewStaticAPIKeyStore hashes the plain keys and indexes all of them by prefix
+func NewStaticAPIKeyStore(keys ...StaticAPIKey) *StaticAPIKeyStore {
+	store := &StaticAPIKeyStore{
+		mutex: new(sync.RWMutex),
+		keys:  make(map[string]*contract.APIKey),
+	}
+
+	for _, static := range keys {
+		key := static.APIKey
+
+		if static.Key != "" {
+			if i := strings.LastIndex(static.Key, APIKeySeparator); i > 0 {
+				key.Prefix = static.Key[:i]
+			}
+
+			key.Hash = HashAPIKey(static.Key)
+		}
+
+		if key.ID == "" {
+			key.ID = key.Prefix
+		}
+
+		store.keys[key.Prefix] = &key
+	}
+
+	return store
+}
+
+// Find ...
+func (s *StaticAPIKeyStore) Find(ctx context.Context, prefix string) (*contract.APIKey, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	key, ok := s.keys[prefix]
+
+	if !ok {
+		return nil, nil
+	}
+
+	found := *key
+
+	return &found, nil
+}
+
+// Touch ...
+func (s *StaticAPIKeyStore) Touch(ctx context.Context, id string, at time.Time) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for _, key := range s.keys {
+		if key.ID == id {
+			key.LastUsedAt = at
+		}
+	}
+
+	return nil
+}
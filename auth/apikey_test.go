@@ -0,0 +1,174 @@
+package auth_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raafvargas/wrapit/auth"
+	"github.com/raafvargas/wrapit/contract"
+	"github.com/stretchr/testify/assert"
+)
+
+type touchingStore struct {
+	*auth.StaticAPIKeyStore
+	touched chan string
+}
+
+func (s *touchingStore) Touch(ctx context.Context, id string, at time.Time) error {
+	s.touched <- id
+	return s.StaticAPIKeyStore.Touch(ctx, id, at)
+}
+
+func TestGenerateAPIKey(t *testing.T) {
+	raw, key, err := auth.GenerateAPIKey("live", "partner", "read")
+
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(raw, key.Prefix+auth.APIKeySeparator))
+	assert.True(t, strings.HasPrefix(key.Prefix, "live_"))
+	assert.Equal(t, auth.HashAPIKey(raw), key.Hash)
+	assert.NotContains(t, key.Hash, raw)
+	assert.Equal(t, []string{"read"}, key.Scopes)
+}
+
+func TestAPIKeyHandler(t *testing.T) {
+	raw, key, _ := auth.GenerateAPIKey("live", "partner", "read", "write")
+	key.Tenant = "acme"
+
+	handler := auth.NewAPIKeyHandler(&auth.APIKeyConfig{Query: "api_key"},
+		auth.NewStaticAPIKeyStore(auth.StaticAPIKey{APIKey: *key}))
+
+	engine := gin.New()
+	engine.GET("me", handler.HTTP(), auth.Authorize("write"), func(ctx *gin.Context) {
+		principal, _ := auth.GetPrincipal(ctx)
+		ctx.String(http.StatusOK, principal.Subject+" "+principal.Tenant)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set(auth.DefaultAPIKeyHeader, raw)
+
+	res := httptest.NewRecorder()
+	engine.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "partner acme", res.Body.String())
+
+	res = httptest.NewRecorder()
+	engine.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/me?api_key="+raw, nil))
+
+	assert.Equal(t, http.StatusOK, res.Code)
+}
+
+func TestAPIKeyHandlerInvalid(t *testing.T) {
+	raw, key, _ := auth.GenerateAPIKey("live", "partner")
+	expiredRaw, expired, _ := auth.GenerateAPIKey("live", "partner")
+	expired.ExpiresAt = time.Now().Add(-time.Minute)
+
+	handler := auth.NewAPIKeyHandler(&auth.APIKeyConfig{},
+		auth.NewStaticAPIKeyStore(auth.StaticAPIKey{APIKey: *key}, auth.StaticAPIKey{APIKey: *expired}))
+
+	tests := map[string]struct {
+		key string
+		err error
+	}{
+		"missing":    {"", auth.ErrMissingAPIKey},
+		"malformed":  {"key", auth.ErrInvalidAPIKey},
+		"unknown":    {"live_unknown.secret", auth.ErrInvalidAPIKey},
+		"wrong hash": {key.Prefix + ".secret", auth.ErrInvalidAPIKey},
+		"expired":    {expiredRaw, auth.ErrExpiredAPIKey},
+	}
+
+	for name, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(auth.DefaultAPIKeyHeader, test.key)
+
+		_, err := handler.Authenticate(req)
+		assert.Equal(t, test.err, err, name)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(auth.DefaultAPIKeyHeader, raw)
+
+	_, err := handler.Authenticate(req)
+	assert.NoError(t, err)
+}
+
+func TestAPIKeyHandlerLastUsed(t *testing.T) {
+	raw, key, _ := auth.GenerateAPIKey("live", "partner")
+
+	store := &touchingStore{
+		StaticAPIKeyStore: auth.NewStaticAPIKeyStore(auth.StaticAPIKey{APIKey: *key}),
+		touched:           make(chan string, 2),
+	}
+
+	handler := auth.NewAPIKeyHandler(&auth.APIKeyConfig{LastUsedInterval: time.Hour}, store)
+
+	_, err := handler.Validate(context.Background(), raw)
+	assert.NoError(t, err)
+	assert.Equal(t, key.ID, <-store.touched)
+
+	_, err = handler.Validate(context.Background(), raw)
+	assert.NoError(t, err)
+
+	select {
+	case <-store.touched:
+		t.Fatal("touched within the interval")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+type blockingStore struct {
+	*auth.StaticAPIKeyStore
+	touched chan string
+	release chan struct{}
+}
+
+func (s *blockingStore) Touch(ctx context.Context, id string, at time.Time) error {
+	s.touched <- id
+	<-s.release
+	return s.StaticAPIKeyStore.Touch(ctx, id, at)
+}
+
+func TestAPIKeyHandlerSingleTouch(t *testing.T) {
+	raw, key, _ := auth.GenerateAPIKey("live", "partner")
+
+	store := &blockingStore{
+		StaticAPIKeyStore: auth.NewStaticAPIKeyStore(auth.StaticAPIKey{APIKey: *key}),
+		touched:           make(chan string, 3),
+		release:           make(chan struct{}),
+	}
+	defer close(store.release)
+
+	handler := auth.NewAPIKeyHandler(&auth.APIKeyConfig{}, store)
+
+	for i := 0; i < 3; i++ {
+		_, err := handler.Validate(context.Background(), raw)
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, key.ID, <-store.touched)
+
+	select {
+	case <-store.touched:
+		t.Fatal("touched while a touch was in flight")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestStaticAPIKeyStorePlainKeys(t *testing.T) {
+	handler := auth.NewAPIKeyHandler(&auth.APIKeyConfig{
+		Keys: []auth.StaticAPIKey{{
+			APIKey: contract.APIKey{Subject: "dev", Scopes: []string{"read"}},
+			Key:    "dev_local.secret",
+		}},
+	}, nil)
+
+	key, err := handler.Validate(context.Background(), "dev_local.secret")
+	assert.NoError(t, err)
+	assert.Equal(t, "dev", key.Subject)
+	assert.Equal(t, "dev_local", key.Prefix)
+}
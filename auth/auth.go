@@ -1,6 +1,12 @@
 package auth
 
-import "github.com/gin-gonic/gin"
+import (
+	"context"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raafvargas/wrapit/contract"
+)
 
 // Config ...
 type Config struct {
@@ -8,6 +14,15 @@ type Config struct {
 	Tenant   string     `yaml:"tenant"`
 	JWKS     string     `yaml:"jwks"`
 	Audience []string   `yaml:"audience"`
+
+	Issuers        []IssuerConfig    `yaml:"issuers"`
+	Algorithms     []string          `yaml:"algorithms"`
+	ClockSkew      time.Duration     `yaml:"clock_skew"`
+	RequiredClaims map[string]string `yaml:"required_claims"`
+	JWKSCache      JWKSConfig        `yaml:"jwks_cache"`
+
+	Authorization AuthorizationConfig `yaml:"authorization"`
+	APIKeys       APIKeyConfig        `yaml:"api_keys"`
 }
 
 // MockConfig ...
@@ -22,8 +37,36 @@ type Handler interface {
 	HTTP() gin.HandlerFunc
 }
 
-// NewHandler ...
-func NewHandler(config *Config) Handler {
+// Runner is implemented by the handlers refreshing their signing keys in the background
+type Runner interface {
+	Run(ctx context.Context) error
+}
+
+// HandlerOption ...
+type HandlerOption func(*handlerOptions)
+
+type handlerOptions struct {
+	apiKeyStore contract.APIKeyStore
+}
+
+// WithAPIKeyStore authenticates API keys through the store instead of the keys of the config
+func WithAPIKeyStore(store contract.APIKeyStore) HandlerOption {
+	return func(o *handlerOptions) {
+		o.apiKeyStore = store
+	}
+}
+
+// NewHandler authenticates bearer tokens, chained with API keys when the config has keys
+// or WithAPIKeyStore is given. The background key refresh isn't started, callers run the
+// handler when it implements Runner, e.g. with app.WithWorker, otherwise keys are refreshed
+// on the request path.
+func NewHandler(config *Config, options ...HandlerOption) Handler {
+	opts := new(handlerOptions)
+
+	for _, o := range options {
+		o(opts)
+	}
+
 	if config.Mock.Enabled {
 		return NewMock(
 			config.Mock.Authenticated,
@@ -31,5 +74,23 @@ func NewHandler(config *Config) Handler {
 		)
 	}
 
-	return NewAuth0Handler(config)
+	if len(config.APIKeys.Keys) == 0 && opts.apiKeyStore == nil {
+		if len(config.Issuers) > 0 {
+			return NewOIDCHandler(config)
+		}
+
+		return NewAuth0Handler(config)
+	}
+
+	apiKeys := NewAPIKeyHandler(&config.APIKeys, opts.apiKeyStore)
+
+	if len(config.Issuers) > 0 {
+		return Chain(apiKeys, NewOIDCHandler(config))
+	}
+
+	if config.JWKS != "" {
+		return Chain(apiKeys, NewAuth0Handler(config))
+	}
+
+	return apiKeys
 }
@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"context"
 	"net/http"
 
 	"github.com/auth0-community/go-auth0"
@@ -11,19 +12,18 @@ import (
 // Auth0Handler ...
 type Auth0Handler struct {
 	validator *auth0.JWTValidator
+	keys      *JWKSCache
 }
 
 // NewAuth0Handler ...
 func NewAuth0Handler(config *Config) *Auth0Handler {
-	handler := &Auth0Handler{}
+	handler := &Auth0Handler{
+		keys: NewJWKSCache(config.JWKS, &config.JWKSCache),
+	}
 
 	handler.validator = auth0.NewValidator(
 		auth0.NewConfiguration(
-			auth0.NewJWKClient(
-				auth0.JWKClientOptions{
-					URI: config.JWKS,
-				},
-				nil),
+			handler.keys,
 			config.Audience,
 			config.Tenant,
 			jose.RS256,
@@ -34,26 +34,38 @@ func NewAuth0Handler(config *Config) *Auth0Handler {
 	return handler
 }
 
+// Run refreshes the keys in the background until ctx is cancelled
+func (h *Auth0Handler) Run(ctx context.Context) error {
+	return h.keys.Run(ctx)
+}
+
+// Healthz fails when the keys are stale
+func (h *Auth0Handler) Healthz(ctx context.Context) error {
+	return h.keys.Healthz(ctx)
+}
+
 // HTTP ...
 func (h *Auth0Handler) HTTP() gin.HandlerFunc {
-	return func(ctx *gin.Context) {
-		token, err := h.validator.ValidateRequest(ctx.Request)
+	return authenticateHTTP(h)
+}
 
-		if err != nil {
-			ctx.Error(err)
-			ctx.AbortWithStatus(http.StatusUnauthorized)
-		}
+// Authenticate ...
+func (h *Auth0Handler) Authenticate(req *http.Request) (*Principal, error) {
+	token, err := h.validator.ValidateRequest(req)
 
-		claims := make(map[string]interface{})
-		if err := h.validator.Claims(ctx.Request, token, &claims); err != nil {
-			ctx.Error(err)
-			ctx.AbortWithStatus(http.StatusUnauthorized)
-		}
+	if err == auth0.ErrTokenNotFound {
+		return nil, ErrMissingToken
+	}
 
-		for key, value := range claims {
-			ctx.Set(key, value)
-		}
+	if err != nil {
+		return nil, err
+	}
 
-		ctx.Next()
+	claims := make(map[string]interface{})
+
+	if err := h.validator.Claims(req, token, &claims); err != nil {
+		return nil, err
 	}
+
+	return NewPrincipal(claims), nil
 }
@@ -1,6 +1,8 @@
 package auth_test
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/raafvargas/wrapit/auth"
@@ -21,3 +23,34 @@ func TestNewHandler(t *testing.T) {
 	handler = auth.NewHandler(config)
 	assert.IsType(t, &auth.Auth0Handler{}, handler)
 }
+
+func TestNewHandlerAPIKeys(t *testing.T) {
+	config := &auth.Config{
+		APIKeys: auth.APIKeyConfig{
+			Keys: []auth.StaticAPIKey{{Key: "dev_local.secret"}},
+		},
+	}
+
+	assert.IsType(t, &auth.APIKeyHandler{}, auth.NewHandler(config))
+
+	config.JWKS = "https://tenant/.well-known/jwks.json"
+	assert.IsType(t, &auth.ChainHandler{}, auth.NewHandler(config))
+
+	config.Issuers = []auth.IssuerConfig{{Issuer: "https://issuer"}}
+	assert.IsType(t, &auth.ChainHandler{}, auth.NewHandler(config))
+}
+
+func TestNewHandlerAPIKeyStore(t *testing.T) {
+	raw, key, err := auth.GenerateAPIKey("live", "partner")
+	assert.NoError(t, err)
+
+	handler := auth.NewHandler(&auth.Config{}, auth.WithAPIKeyStore(auth.NewStaticAPIKeyStore(auth.StaticAPIKey{APIKey: *key})))
+	assert.IsType(t, &auth.APIKeyHandler{}, handler)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(auth.DefaultAPIKeyHeader, raw)
+
+	principal, err := handler.(*auth.APIKeyHandler).Authenticate(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "partner", principal.Subject)
+}
@@ -1,28 +1,253 @@
 package auth
 
 import (
+	"fmt"
+	"io/ioutil"
 	"net/http"
+	"path/filepath"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"gopkg.in/yaml.v2"
 )
 
-// Authorize ...
-func Authorize(scope string) gin.HandlerFunc {
-	return func(ctx *gin.Context) {
-		permissions, exists := ctx.Get("permissions")
+var (
+	// DefaultScopeClaims are the claims holding the granted scopes, as arrays or space-delimited strings
+	DefaultScopeClaims = []string{"permissions", "scope", "scp"}
+
+	// DefaultRolesClaim ...
+	DefaultRolesClaim = "roles"
+
+	// DefaultAuthorizer reads the default scope claims without roles
+	DefaultAuthorizer = NewAuthorizer(nil)
+)
+
+// Scopes is the set of scopes granted to a request
+type Scopes map[string]bool
+
+// Policy decides whether a request with the given scopes is allowed
+type Policy func(ctx *gin.Context, scopes Scopes) bool
+
+// AuthorizationConfig ...
+type AuthorizationConfig struct {
+	ScopeClaims []string            `yaml:"scope_claims"`
+	RolesClaim  string              `yaml:"roles_claim"`
+	Roles       map[string][]string `yaml:"roles"`
+	RolesFile   string              `yaml:"roles_file"`
+}
+
+// Authorizer enforces policies against the scopes of the request, including the
+// permissions granted by its roles
+type Authorizer struct {
+	scopeClaims []string
+	rolesClaim  string
+	roles       map[string][]string
+}
+
+// NewAuthorizer ...
+func NewAuthorizer(config *AuthorizationConfig) *Authorizer {
+	authorizer := &Authorizer{
+		scopeClaims: DefaultScopeClaims,
+		rolesClaim:  DefaultRolesClaim,
+		roles:       make(map[string][]string),
+	}
+
+	if config == nil {
+		return authorizer
+	}
+
+	if len(config.ScopeClaims) > 0 {
+		authorizer.scopeClaims = config.ScopeClaims
+	}
+
+	if config.RolesClaim != "" {
+		authorizer.rolesClaim = config.RolesClaim
+	}
+
+	for role, permissions := range config.Roles {
+		authorizer.roles[role] = permissions
+	}
+
+	return authorizer
+}
+
+// LoadAuthorizer creates the authorizer, merging the roles of config.RolesFile
+func LoadAuthorizer(config *AuthorizationConfig) (*Authorizer, error) {
+	authorizer := NewAuthorizer(config)
 
-		if !exists {
+	if config == nil || config.RolesFile == "" {
+		return authorizer, nil
+	}
+
+	filename, _ := filepath.Abs(config.RolesFile)
+
+	data, err := ioutil.ReadFile(filename)
+
+	if err != nil {
+		return nil, err
+	}
+
+	roles := make(map[string][]string)
+
+	if err := yaml.Unmarshal(data, &roles); err != nil {
+		return nil, err
+	}
+
+	for role, permissions := range roles {
+		authorizer.roles[role] = append(authorizer.roles[role], permissions...)
+	}
+
+	return authorizer, nil
+}
+
+// Require aborts with 403 unless the policy allows the request
+func (a *Authorizer) Require(policy Policy) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if !policy(ctx, a.Scopes(ctx)) {
 			ctx.AbortWithStatus(http.StatusForbidden)
 			return
 		}
 
-		for _, permission := range permissions.([]interface{}) {
-			if permission == scope {
-				ctx.Next()
-				return
+		ctx.Next()
+	}
+}
+
+// Scopes returns the scopes of the principal, the scopes of the request claims and the
+// permissions of its roles. Principals without token claims, e.g. API keys, only carry
+// their scopes in Principal.Scopes.
+func (a *Authorizer) Scopes(ctx *gin.Context) Scopes {
+	scopes := make(Scopes)
+
+	if principal, ok := GetPrincipal(ctx); ok {
+		for _, scope := range principal.Scopes {
+			scopes[scope] = true
+		}
+	}
+
+	for _, name := range a.scopeClaims {
+		value, _ := claim(ctx, name)
+
+		for _, scope := range claimValues(value) {
+			scopes[scope] = true
+		}
+	}
+
+	if len(a.roles) == 0 {
+		return scopes
+	}
+
+	value, _ := claim(ctx, a.rolesClaim)
+
+	for _, role := range claimValues(value) {
+		for _, permission := range a.roles[role] {
+			scopes[permission] = true
+		}
+	}
+
+	return scopes
+}
+
+// Authorize requires the scope using the DefaultAuthorizer
+func Authorize(scope string) gin.HandlerFunc {
+	return DefaultAuthorizer.Require(Scope(scope))
+}
+
+// Scope requires the scope
+func Scope(scope string) Policy {
+	return func(ctx *gin.Context, scopes Scopes) bool {
+		return scopes[scope]
+	}
+}
+
+// AllScopes requires every scope
+func AllScopes(scopes ...string) Policy {
+	policies := make([]Policy, len(scopes))
+
+	for i, scope := range scopes {
+		policies[i] = Scope(scope)
+	}
+
+	return All(policies...)
+}
+
+// AnyScope requires at least one of the scopes
+func AnyScope(scopes ...string) Policy {
+	policies := make([]Policy, len(scopes))
+
+	for i, scope := range scopes {
+		policies[i] = Scope(scope)
+	}
+
+	return Any(policies...)
+}
+
+// All allows the request when every policy does
+func All(policies ...Policy) Policy {
+	return func(ctx *gin.Context, scopes Scopes) bool {
+		for _, policy := range policies {
+			if !policy(ctx, scopes) {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+// Any allows the request when at least one policy does
+func Any(policies ...Policy) Policy {
+	return func(ctx *gin.Context, scopes Scopes) bool {
+		for _, policy := range policies {
+			if policy(ctx, scopes) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// Not ...
+func Not(policy Policy) Policy {
+	return func(ctx *gin.Context, scopes Scopes) bool {
+		return !policy(ctx, scopes)
+	}
+}
+
+// ParamMatchesClaim requires the route param to equal the claim, e.g. the tenant_id
+// path param and the tenant claim of the token
+func ParamMatchesClaim(param, name string) Policy {
+	return func(ctx *gin.Context, scopes Scopes) bool {
+		value, exists := claim(ctx, name)
+
+		if !exists || value == nil {
+			return false
+		}
+
+		expected := ctx.Param(param)
+
+		return expected != "" && fmt.Sprint(value) == expected
+	}
+}
+
+// claimValues reads array claims and space-delimited string claims
+func claimValues(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return strings.Fields(v)
+	case []string:
+		return v
+	case []interface{}:
+		values := make([]string, 0, len(v))
+
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
 			}
 		}
 
-		ctx.AbortWithStatus(http.StatusForbidden)
+		return values
+	default:
+		return nil
 	}
 }
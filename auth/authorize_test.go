@@ -1,12 +1,15 @@
 package auth_test
 
 import (
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/raafvargas/wrapit/auth"
+	"github.com/raafvargas/wrapit/contract"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -45,3 +48,138 @@ func TestAuthorizeWithoutPermissions(t *testing.T) {
 
 	assert.Equal(t, http.StatusForbidden, res.Code)
 }
+
+func TestAuthorizeInvalidClaim(t *testing.T) {
+	res := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(res)
+
+	ctx.Set("permissions", 10)
+
+	assert.NotPanics(t, func() {
+		auth.Authorize("read")(ctx)
+	})
+	assert.Equal(t, http.StatusForbidden, res.Code)
+}
+
+func TestAuthorizeSpaceDelimitedScope(t *testing.T) {
+	res := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(res)
+
+	ctx.Set("scope", "openid read write")
+
+	auth.Authorize("write")(ctx)
+
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.False(t, ctx.IsAborted())
+}
+
+func TestAuthorizerCustomClaims(t *testing.T) {
+	authorizer := auth.NewAuthorizer(&auth.AuthorizationConfig{
+		ScopeClaims: []string{"https://example.com/scopes"},
+	})
+
+	res := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(res)
+
+	ctx.Set("permissions", []interface{}{"read"})
+	ctx.Set("https://example.com/scopes", []string{"write"})
+
+	assert.Equal(t, auth.Scopes{"write": true}, authorizer.Scopes(ctx))
+}
+
+func TestAuthorizerCustomClaimsAPIKey(t *testing.T) {
+	authorizer := auth.NewAuthorizer(&auth.AuthorizationConfig{
+		ScopeClaims: []string{"https://example.com/scopes"},
+	})
+
+	handler := auth.NewAPIKeyHandler(&auth.APIKeyConfig{
+		Keys: []auth.StaticAPIKey{{
+			APIKey: contract.APIKey{Subject: "service", Scopes: []string{"read"}},
+			Key:    "live_test.secret",
+		}},
+	}, nil)
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(auth.DefaultAPIKeyHeader, "live_test.secret")
+
+	principal, err := handler.Authenticate(req)
+	assert.NoError(t, err)
+
+	res := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(res)
+	auth.SetPrincipal(ctx, principal)
+
+	authorizer.Require(auth.Scope("read"))(ctx)
+
+	assert.Equal(t, http.StatusOK, res.Code)
+}
+
+func TestAuthorizerPolicies(t *testing.T) {
+	scopes := auth.Scopes{"read": true, "write": true}
+
+	tests := map[string]struct {
+		policy  auth.Policy
+		allowed bool
+	}{
+		"scope":         {auth.Scope("read"), true},
+		"missing scope": {auth.Scope("delete"), false},
+		"all":           {auth.AllScopes("read", "write"), true},
+		"not all":       {auth.AllScopes("read", "delete"), false},
+		"any":           {auth.AnyScope("delete", "write"), true},
+		"none":          {auth.AnyScope("delete", "admin"), false},
+		"not":           {auth.Not(auth.Scope("admin")), true},
+		"nested":        {auth.All(auth.Scope("read"), auth.Any(auth.Scope("admin"), auth.Not(auth.Scope("write")))), false},
+	}
+
+	for name, test := range tests {
+		assert.Equal(t, test.allowed, test.policy(nil, scopes), name)
+	}
+}
+
+func TestAuthorizerRoles(t *testing.T) {
+	file, err := ioutil.TempFile(os.TempDir(), "roles.yaml")
+	assert.NoError(t, err)
+	defer os.Remove(file.Name())
+
+	file.WriteString("admin:\n  - delete\n")
+	file.Close()
+
+	authorizer, err := auth.LoadAuthorizer(&auth.AuthorizationConfig{
+		Roles:     map[string][]string{"editor": {"read", "write"}},
+		RolesFile: file.Name(),
+	})
+	assert.NoError(t, err)
+
+	res := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(res)
+	ctx.Set("roles", []interface{}{"editor", "admin"})
+
+	assert.Equal(t, auth.Scopes{"read": true, "write": true, "delete": true}, authorizer.Scopes(ctx))
+
+	_, err = auth.LoadAuthorizer(&auth.AuthorizationConfig{RolesFile: "missing.yaml"})
+	assert.Error(t, err)
+}
+
+func TestAuthorizerParamMatchesClaim(t *testing.T) {
+	engine := gin.New()
+	engine.GET("tenants/:tenant_id", func(ctx *gin.Context) {
+		ctx.Set("tenant", ctx.GetHeader("X-Tenant"))
+	}, auth.DefaultAuthorizer.Require(auth.ParamMatchesClaim("tenant_id", "tenant")), func(ctx *gin.Context) {
+		ctx.Status(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/tenants/a", nil)
+	req.Header.Set("X-Tenant", "a")
+
+	res := httptest.NewRecorder()
+	engine.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusNoContent, res.Code)
+
+	req.Header.Set("X-Tenant", "b")
+
+	res = httptest.NewRecorder()
+	engine.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusForbidden, res.Code)
+}
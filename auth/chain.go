@@ -0,0 +1,105 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Authenticator authenticates a request with a single method such as bearer tokens or
+// API keys. It returns ErrMissingToken or ErrMissingAPIKey when the request doesn't
+// carry its credentials.
+type Authenticator interface {
+	Authenticate(req *http.Request) (*Principal, error)
+}
+
+// ChainHandler authenticates requests with the first authenticator whose credentials
+// are present
+type ChainHandler struct {
+	authenticators []Authenticator
+}
+
+// Chain accepts any of the authentication methods, e.g. API keys or bearer tokens
+func Chain(authenticators ...Authenticator) *ChainHandler {
+	return &ChainHandler{authenticators: authenticators}
+}
+
+// HTTP ...
+func (h *ChainHandler) HTTP() gin.HandlerFunc {
+	return authenticateHTTP(h)
+}
+
+// Run runs the background key refresh of the authenticators implementing Runner until ctx is cancelled.
+// It returns the errors of all the runners once they stop.
+func (h *ChainHandler) Run(ctx context.Context) error {
+	wg := new(sync.WaitGroup)
+	mutex := new(sync.Mutex)
+	errs := []string{}
+
+	for _, authenticator := range h.authenticators {
+		runner, ok := authenticator.(Runner)
+
+		if !ok {
+			continue
+		}
+
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if err := runner.Run(ctx); err != nil {
+				mutex.Lock()
+				errs = append(errs, err.Error())
+				mutex.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+
+	return nil
+}
+
+// Authenticate fails on invalid credentials without trying the next authenticators
+func (h *ChainHandler) Authenticate(req *http.Request) (*Principal, error) {
+	for _, authenticator := range h.authenticators {
+		principal, err := authenticator.Authenticate(req)
+
+		if missingCredentials(err) {
+			continue
+		}
+
+		return principal, err
+	}
+
+	return nil, ErrMissingToken
+}
+
+func authenticateHTTP(authenticator Authenticator) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		principal, err := authenticator.Authenticate(ctx.Request)
+
+		if err != nil {
+			ctx.Error(err)
+			ctx.AbortWithStatus(http.StatusUnauthorized)
+			return
+		}
+
+		SetPrincipal(ctx, principal)
+
+		ctx.Next()
+	}
+}
+
+func missingCredentials(err error) bool {
+	return errors.Is(err, ErrMissingToken) || errors.Is(err, ErrMissingAPIKey)
+}
@@ -0,0 +1,102 @@
+package auth_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raafvargas/wrapit/auth"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/square/go-jose.v2"
+)
+
+func TestChain(t *testing.T) {
+	provider := newOIDCProvider()
+	defer provider.server.Close()
+
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	signer := provider.addKey("rs", rsaKey, &rsaKey.PublicKey, jose.RS256)
+
+	raw, key, _ := auth.GenerateAPIKey("live", "partner")
+
+	handler := auth.Chain(
+		auth.NewAPIKeyHandler(&auth.APIKeyConfig{}, auth.NewStaticAPIKeyStore(auth.StaticAPIKey{APIKey: *key})),
+		auth.NewOIDCHandler(oidcConfig(provider)),
+	)
+
+	engine := gin.New()
+	engine.GET("me", handler.HTTP(), func(ctx *gin.Context) {
+		principal, _ := auth.GetPrincipal(ctx)
+		ctx.String(http.StatusOK, principal.Subject)
+	})
+
+	tests := map[string]struct {
+		header string
+		value  string
+		code   int
+		body   string
+	}{
+		"api key": {auth.DefaultAPIKeyHeader, raw, http.StatusOK, "partner"},
+		"bearer": {"Authorization", "Bearer " + provider.token(signer, provider.server.URL,
+			time.Now().Add(time.Hour), map[string]interface{}{"tenant": "a"}), http.StatusOK, "user"},
+		"invalid api key": {auth.DefaultAPIKeyHeader, key.Prefix + ".invalid", http.StatusUnauthorized, ""},
+		"invalid bearer":  {"Authorization", "Bearer invalid", http.StatusUnauthorized, ""},
+		"missing":         {"X-Other", "value", http.StatusUnauthorized, ""},
+	}
+
+	for name, test := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/me", nil)
+		req.Header.Set(test.header, test.value)
+
+		res := httptest.NewRecorder()
+		engine.ServeHTTP(res, req)
+
+		assert.Equal(t, test.code, res.Code, name)
+		assert.Equal(t, test.body, res.Body.String(), name)
+	}
+}
+
+func TestChainRun(t *testing.T) {
+	provider := newOIDCProvider()
+	defer provider.server.Close()
+
+	handler := auth.Chain(
+		auth.NewAPIKeyHandler(&auth.APIKeyConfig{}, auth.NewStaticAPIKeyStore()),
+		auth.NewOIDCHandler(oidcConfig(provider)),
+	)
+
+	var _ auth.Runner = handler
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	assert.NoError(t, handler.Run(ctx))
+}
+
+type failingRunner struct {
+	err error
+}
+
+func (*failingRunner) Authenticate(req *http.Request) (*auth.Principal, error) {
+	return nil, auth.ErrMissingToken
+}
+
+func (r *failingRunner) Run(ctx context.Context) error {
+	return r.err
+}
+
+func TestChainRunErrors(t *testing.T) {
+	handler := auth.Chain(
+		&failingRunner{err: errors.New("jwks unavailable")},
+		auth.NewAPIKeyHandler(&auth.APIKeyConfig{}, auth.NewStaticAPIKeyStore()),
+		&failingRunner{},
+	)
+
+	assert.EqualError(t, handler.Run(context.Background()), "jwks unavailable")
+}
@@ -0,0 +1,317 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/auth0-community/go-auth0"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/square/go-jose.v2"
+)
+
+var (
+	// DefaultJWKSRefreshInterval ...
+	DefaultJWKSRefreshInterval = time.Hour
+
+	// DefaultJWKSMinRefreshInterval limits the refetches triggered by unknown key ids and failures
+	DefaultJWKSMinRefreshInterval = 30 * time.Second
+
+	// DefaultJWKSMaxStaleness ...
+	DefaultJWKSMaxStaleness = 24 * time.Hour
+
+	// ErrKeysNotLoaded ...
+	ErrKeysNotLoaded = errors.New("jwks keys were never loaded")
+
+	// ErrStaleKeys ...
+	ErrStaleKeys = errors.New("jwks keys are stale")
+
+	jwksMetricsOnce = new(sync.Once)
+	jwksRefreshes   *prometheus.CounterVec
+	jwksRefreshedAt *prometheus.GaugeVec
+	jwksKeys        *prometheus.GaugeVec
+)
+
+// JWKSConfig ...
+type JWKSConfig struct {
+	RefreshInterval    time.Duration `yaml:"refresh_interval"`
+	MinRefreshInterval time.Duration `yaml:"min_refresh_interval"`
+	MaxStaleness       time.Duration `yaml:"max_staleness"`
+}
+
+// JWKSCache keeps the signing keys of an issuer out of the request path. Keys are refreshed
+// in the background every RefreshInterval and when a token uses an unknown key id, at most
+// once every MinRefreshInterval. Failed refreshes keep the last known good keys.
+type JWKSCache struct {
+	name    string
+	url     string
+	resolve func(context.Context) (string, error)
+	client  *http.Client
+	config  JWKSConfig
+
+	mutex       *sync.RWMutex
+	keys        *jose.JSONWebKeySet
+	refreshedAt time.Time
+	attemptedAt time.Time
+	refreshing  *sync.Mutex
+	inFlight    int32
+}
+
+// NewJWKSCache ...
+func NewJWKSCache(url string, config *JWKSConfig) *JWKSCache {
+	return newJWKSCache(url, func(context.Context) (string, error) {
+		return url, nil
+	}, config)
+}
+
+func newJWKSCache(name string, resolve func(context.Context) (string, error), config *JWKSConfig) *JWKSCache {
+	cache := &JWKSCache{
+		name:       name,
+		resolve:    resolve,
+		client:     &http.Client{Timeout: 10 * time.Second},
+		mutex:      new(sync.RWMutex),
+		refreshing: new(sync.Mutex),
+	}
+
+	if config != nil {
+		cache.config = *config
+	}
+
+	if cache.config.RefreshInterval == 0 {
+		cache.config.RefreshInterval = DefaultJWKSRefreshInterval
+	}
+
+	if cache.config.MinRefreshInterval == 0 {
+		cache.config.MinRefreshInterval = DefaultJWKSMinRefreshInterval
+	}
+
+	if cache.config.MaxStaleness == 0 {
+		cache.config.MaxStaleness = DefaultJWKSMaxStaleness
+	}
+
+	registerJWKSMetrics()
+
+	return cache
+}
+
+// Run refreshes the keys every RefreshInterval, retrying failures every MinRefreshInterval,
+// until ctx is cancelled. It must be started by the caller, e.g. with app.WithWorker.
+// Without Run keys are refreshed when requested after the interval.
+func (c *JWKSCache) Run(ctx context.Context) error {
+	for {
+		next := c.config.RefreshInterval
+
+		if err := c.Refresh(ctx); err != nil {
+			next = c.config.MinRefreshInterval
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(next):
+		}
+	}
+}
+
+// Key returns the signing key with the given id, any signing key when kid is empty
+func (c *JWKSCache) Key(ctx context.Context, kid string) (*jose.JSONWebKey, error) {
+	c.mutex.RLock()
+	key := findKey(c.keys, kid)
+	refreshedAt := c.refreshedAt
+	c.mutex.RUnlock()
+
+	if key != nil {
+		// a single background refresh at a time, the other requests keep the current keys
+		if time.Since(refreshedAt) > c.config.RefreshInterval && atomic.CompareAndSwapInt32(&c.inFlight, 0, 1) {
+			go func() {
+				defer atomic.StoreInt32(&c.inFlight, 0)
+				c.refreshLimited(context.Background())
+			}()
+		}
+
+		return key, nil
+	}
+
+	if err := c.refreshLimited(ctx); err != nil && !errors.Is(err, errRefreshLimited) {
+		return nil, err
+	}
+
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	if key := findKey(c.keys, kid); key != nil {
+		return key, nil
+	}
+
+	return nil, ErrUnknownKey
+}
+
+// GetSecret implements auth0.SecretProvider
+func (c *JWKSCache) GetSecret(r *http.Request) (interface{}, error) {
+	token, err := auth0.FromHeader(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(token.Headers) < 1 {
+		return nil, auth0.ErrNoJWTHeaders
+	}
+
+	return c.Key(r.Context(), token.Headers[0].KeyID)
+}
+
+// Healthz fails when the keys were never loaded or weren't refreshed within MaxStaleness
+func (c *JWKSCache) Healthz(ctx context.Context) error {
+	c.mutex.RLock()
+	refreshedAt := c.refreshedAt
+	c.mutex.RUnlock()
+
+	if refreshedAt.IsZero() {
+		if err := c.refreshLimited(ctx); err != nil {
+			return fmt.Errorf("%w: %s", ErrKeysNotLoaded, err)
+		}
+
+		return nil
+	}
+
+	if time.Since(refreshedAt) > c.config.MaxStaleness {
+		return fmt.Errorf("%w: last refreshed at %s", ErrStaleKeys, refreshedAt.Format(time.RFC3339))
+	}
+
+	return nil
+}
+
+// Refresh fetches the keys, keeping the current ones when it fails
+func (c *JWKSCache) Refresh(ctx context.Context) error {
+	c.refreshing.Lock()
+	defer c.refreshing.Unlock()
+
+	return c.refresh(ctx)
+}
+
+var errRefreshLimited = errors.New("jwks refresh rate limited")
+
+func (c *JWKSCache) refreshLimited(ctx context.Context) error {
+	c.refreshing.Lock()
+	defer c.refreshing.Unlock()
+
+	c.mutex.RLock()
+	attemptedAt := c.attemptedAt
+	c.mutex.RUnlock()
+
+	if time.Since(attemptedAt) < c.config.MinRefreshInterval {
+		jwksRefreshes.WithLabelValues(c.name, "rate_limited").Inc()
+		return errRefreshLimited
+	}
+
+	return c.refresh(ctx)
+}
+
+func (c *JWKSCache) refresh(ctx context.Context) error {
+	c.mutex.Lock()
+	c.attemptedAt = time.Now()
+	c.mutex.Unlock()
+
+	keys, err := c.fetch(ctx)
+
+	if err != nil {
+		jwksRefreshes.WithLabelValues(c.name, "error").Inc()
+		logrus.WithError(err).
+			WithField("jwks", c.name).
+			Warn("couldn't refresh jwks, keeping the last known keys")
+		return err
+	}
+
+	c.mutex.Lock()
+	c.keys = keys
+	c.refreshedAt = time.Now()
+	c.mutex.Unlock()
+
+	jwksRefreshes.WithLabelValues(c.name, "success").Inc()
+	jwksRefreshedAt.WithLabelValues(c.name).SetToCurrentTime()
+	jwksKeys.WithLabelValues(c.name).Set(float64(len(keys.Keys)))
+
+	return nil
+}
+
+func (c *JWKSCache) fetch(ctx context.Context) (*jose.JSONWebKeySet, error) {
+	url, err := c.resolve(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	keys := new(jose.JSONWebKeySet)
+
+	if err := getJSON(ctx, c.client, url, keys); err != nil {
+		return nil, err
+	}
+
+	if len(keys.Keys) == 0 {
+		return nil, auth0.ErrNoKeyFound
+	}
+
+	return keys, nil
+}
+
+func getJSON(ctx context.Context, client *http.Client, url string, dest interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+
+	if err != nil {
+		return err
+	}
+
+	res, err := client.Do(req)
+
+	if err != nil {
+		return err
+	}
+
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned %d", url, res.StatusCode)
+	}
+
+	return json.NewDecoder(res.Body).Decode(dest)
+}
+
+func registerJWKSMetrics() {
+	jwksMetricsOnce.Do(func() {
+		jwksRefreshes = registerCollector(prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "auth_jwks_refresh_total",
+			Help: "JWKS refreshes by issuer and result.",
+		}, []string{"jwks", "result"})).(*prometheus.CounterVec)
+
+		jwksRefreshedAt = registerCollector(prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "auth_jwks_last_refresh_timestamp_seconds",
+			Help: "Unix time of the last successful JWKS refresh.",
+		}, []string{"jwks"})).(*prometheus.GaugeVec)
+
+		jwksKeys = registerCollector(prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "auth_jwks_keys",
+			Help: "Keys in the cached JWKS.",
+		}, []string{"jwks"})).(*prometheus.GaugeVec)
+	})
+}
+
+func registerCollector(collector prometheus.Collector) prometheus.Collector {
+	if err := prometheus.Register(collector); err != nil {
+		var registered prometheus.AlreadyRegisteredError
+
+		if errors.As(err, &registered) {
+			return registered.ExistingCollector
+		}
+
+		panic(err)
+	}
+
+	return collector
+}
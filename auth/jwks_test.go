@@ -0,0 +1,191 @@
+package auth_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/raafvargas/wrapit/auth"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/square/go-jose.v2"
+)
+
+type jwksServer struct {
+	*httptest.Server
+	keys jose.JSONWebKeySet
+	down int32
+	slow int32
+	hits int32
+}
+
+func newJWKSServer(kids ...string) *jwksServer {
+	server := new(jwksServer)
+
+	for _, kid := range kids {
+		key, _ := rsa.GenerateKey(rand.Reader, 2048)
+		server.keys.Keys = append(server.keys.Keys, jose.JSONWebKey{Key: &key.PublicKey, KeyID: kid, Use: "sig"})
+	}
+
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&server.hits, 1)
+
+		if atomic.LoadInt32(&server.slow) == 1 {
+			time.Sleep(100 * time.Millisecond)
+		}
+
+		if atomic.LoadInt32(&server.down) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"keys":[`))
+		for i, key := range server.keys.Keys {
+			if i > 0 {
+				w.Write([]byte(","))
+			}
+			data, _ := key.MarshalJSON()
+			w.Write(data)
+		}
+		w.Write([]byte(`]}`))
+	}))
+
+	return server
+}
+
+func TestJWKSCacheKey(t *testing.T) {
+	server := newJWKSServer("a")
+	defer server.Close()
+
+	cache := auth.NewJWKSCache(server.URL, nil)
+
+	key, err := cache.Key(context.Background(), "a")
+	assert.NoError(t, err)
+	assert.Equal(t, "a", key.KeyID)
+
+	_, err = cache.Key(context.Background(), "a")
+	assert.NoError(t, err)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&server.hits))
+}
+
+func TestJWKSCacheUnknownKeyRateLimited(t *testing.T) {
+	server := newJWKSServer("a")
+	defer server.Close()
+
+	cache := auth.NewJWKSCache(server.URL, &auth.JWKSConfig{MinRefreshInterval: time.Hour})
+
+	_, err := cache.Key(context.Background(), "a")
+	assert.NoError(t, err)
+
+	for i := 0; i < 5; i++ {
+		_, err = cache.Key(context.Background(), "unknown")
+		assert.Equal(t, auth.ErrUnknownKey, err)
+	}
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&server.hits))
+}
+
+func TestJWKSCacheUnknownKeyRefresh(t *testing.T) {
+	server := newJWKSServer("a")
+	defer server.Close()
+
+	cache := auth.NewJWKSCache(server.URL, &auth.JWKSConfig{MinRefreshInterval: time.Nanosecond})
+
+	_, err := cache.Key(context.Background(), "a")
+	assert.NoError(t, err)
+
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	server.keys.Keys = append(server.keys.Keys, jose.JSONWebKey{Key: &key.PublicKey, KeyID: "b", Use: "sig"})
+
+	found, err := cache.Key(context.Background(), "b")
+	assert.NoError(t, err)
+	assert.Equal(t, "b", found.KeyID)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&server.hits))
+}
+
+func TestJWKSCacheSingleBackgroundRefresh(t *testing.T) {
+	server := newJWKSServer("a")
+	defer server.Close()
+
+	cache := auth.NewJWKSCache(server.URL, &auth.JWKSConfig{
+		RefreshInterval:    time.Nanosecond,
+		MinRefreshInterval: time.Nanosecond,
+	})
+	assert.NoError(t, cache.Refresh(context.Background()))
+
+	atomic.StoreInt32(&server.slow, 1)
+
+	for i := 0; i < 20; i++ {
+		_, err := cache.Key(context.Background(), "a")
+		assert.NoError(t, err)
+	}
+
+	time.Sleep(300 * time.Millisecond)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&server.hits))
+}
+
+func TestJWKSCacheKeepsKeysDuringOutage(t *testing.T) {
+	server := newJWKSServer("a")
+	defer server.Close()
+
+	cache := auth.NewJWKSCache(server.URL, nil)
+	assert.NoError(t, cache.Refresh(context.Background()))
+
+	atomic.StoreInt32(&server.down, 1)
+	assert.Error(t, cache.Refresh(context.Background()))
+
+	key, err := cache.Key(context.Background(), "a")
+	assert.NoError(t, err)
+	assert.Equal(t, "a", key.KeyID)
+	assert.NoError(t, cache.Healthz(context.Background()))
+}
+
+func TestJWKSCacheHealthz(t *testing.T) {
+	server := newJWKSServer("a")
+	atomic.StoreInt32(&server.down, 1)
+	defer server.Close()
+
+	cache := auth.NewJWKSCache(server.URL, &auth.JWKSConfig{
+		MinRefreshInterval: time.Nanosecond,
+		MaxStaleness:       50 * time.Millisecond,
+	})
+
+	assert.True(t, errors.Is(cache.Healthz(context.Background()), auth.ErrKeysNotLoaded))
+
+	atomic.StoreInt32(&server.down, 0)
+	assert.NoError(t, cache.Healthz(context.Background()))
+
+	atomic.StoreInt32(&server.down, 1)
+	time.Sleep(100 * time.Millisecond)
+
+	assert.True(t, errors.Is(cache.Healthz(context.Background()), auth.ErrStaleKeys))
+}
+
+func TestJWKSCacheRun(t *testing.T) {
+	server := newJWKSServer("a")
+	defer server.Close()
+
+	cache := auth.NewJWKSCache(server.URL, &auth.JWKSConfig{RefreshInterval: 10 * time.Millisecond})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	assert.NoError(t, cache.Run(ctx))
+	assert.True(t, atomic.LoadInt32(&server.hits) > 1)
+}
+
+func TestJWKSCacheGetSecret(t *testing.T) {
+	server := newJWKSServer("a")
+	defer server.Close()
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+
+	_, err := auth.NewJWKSCache(server.URL, nil).GetSecret(req)
+	assert.Error(t, err)
+}
@@ -28,8 +28,6 @@ func (h *MockHandler) HTTP() gin.HandlerFunc {
 			return
 		}
 
-		for k, v := range h.claims {
-			ctx.Set(k, v)
-		}
+		SetPrincipal(ctx, NewPrincipal(h.claims))
 	}
 }
@@ -21,7 +21,10 @@ func TestMockHandler(t *testing.T) {
 	handler.HTTP()(ctx)
 
 	assert.Equal(t, http.StatusOK, res.Code)
-	assert.Equal(t, "b", ctx.GetString("a"))
+	principal, ok := auth.GetPrincipal(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "b", principal.Claims["a"])
+	assert.Empty(t, ctx.GetString("a"))
 }
 
 func TestMockHandlerUnauthorized(t *testing.T) {
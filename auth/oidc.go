@@ -0,0 +1,314 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+var (
+	// DiscoveryPath ...
+	DiscoveryPath = "/.well-known/openid-configuration"
+
+	// DefaultAlgorithms are the asymmetric algorithms accepted when Config.Algorithms is empty
+	DefaultAlgorithms = []string{
+		string(jose.RS256), string(jose.RS384), string(jose.RS512),
+		string(jose.ES256), string(jose.ES384), string(jose.ES512),
+		string(jose.PS256), string(jose.PS384), string(jose.PS512),
+	}
+
+	// DefaultClockSkew ...
+	DefaultClockSkew = time.Minute
+
+	// ErrMissingToken ...
+	ErrMissingToken = errors.New("missing bearer token")
+
+	// ErrUntrustedIssuer ...
+	ErrUntrustedIssuer = errors.New("untrusted token issuer")
+
+	// ErrUnsupportedAlgorithm ...
+	ErrUnsupportedAlgorithm = errors.New("unsupported token algorithm")
+
+	// ErrUnknownKey ...
+	ErrUnknownKey = errors.New("unknown token signing key")
+
+	// ErrInvalidAudience ...
+	ErrInvalidAudience = errors.New("invalid token audience")
+
+	// ErrMissingExpiry ...
+	ErrMissingExpiry = errors.New("missing token expiry")
+
+	// ErrMissingClaim ...
+	ErrMissingClaim = errors.New("missing required claim")
+)
+
+// IssuerConfig is a trusted OIDC issuer. The JWKS is discovered from the issuer metadata unless set.
+// The audience defaults to Config.Audience, tokens of issuers without any audience are rejected.
+type IssuerConfig struct {
+	Issuer   string   `yaml:"issuer"`
+	JWKS     string   `yaml:"jwks"`
+	Audience []string `yaml:"audience"`
+}
+
+// OIDCHandler validates bearer tokens of any trusted OIDC issuer
+type OIDCHandler struct {
+	issuers        map[string]*oidcIssuer
+	algorithms     map[string]bool
+	clockSkew      time.Duration
+	requiredClaims map[string]string
+	client         *http.Client
+}
+
+type oidcIssuer struct {
+	config *IssuerConfig
+	keys   *JWKSCache
+}
+
+type discoveryDocument struct {
+	Issuer  string `json:"issuer"`
+	JWKSURI string `json:"jwks_uri"`
+}
+
+// NewOIDCHandler creates the handler. The issuer metadata and keys are fetched on first use
+// and refreshed as configured by Config.JWKSCache.
+func NewOIDCHandler(config *Config) *OIDCHandler {
+	handler := &OIDCHandler{
+		issuers:        make(map[string]*oidcIssuer),
+		algorithms:     make(map[string]bool),
+		clockSkew:      config.ClockSkew,
+		requiredClaims: config.RequiredClaims,
+		client:         &http.Client{Timeout: 10 * time.Second},
+	}
+
+	if handler.clockSkew == 0 {
+		handler.clockSkew = DefaultClockSkew
+	}
+
+	algorithms := config.Algorithms
+
+	if len(algorithms) == 0 {
+		algorithms = DefaultAlgorithms
+	}
+
+	for _, algorithm := range algorithms {
+		handler.algorithms[algorithm] = true
+	}
+
+	for _, issuer := range config.Issuers {
+		issuer := issuer
+
+		if len(issuer.Audience) == 0 {
+			issuer.Audience = config.Audience
+		}
+
+		handler.issuers[strings.TrimSuffix(issuer.Issuer, "/")] = &oidcIssuer{
+			config: &issuer,
+			keys:   newJWKSCache(issuer.Issuer, handler.resolver(&issuer), &config.JWKSCache),
+		}
+	}
+
+	return handler
+}
+
+// Run refreshes the keys of every issuer in the background until ctx is cancelled
+func (h *OIDCHandler) Run(ctx context.Context) error {
+	wg := new(sync.WaitGroup)
+
+	for _, issuer := range h.issuers {
+		wg.Add(1)
+
+		go func(keys *JWKSCache) {
+			defer wg.Done()
+			keys.Run(ctx)
+		}(issuer.keys)
+	}
+
+	wg.Wait()
+
+	return nil
+}
+
+// Healthz fails when the keys of any issuer are stale
+func (h *OIDCHandler) Healthz(ctx context.Context) error {
+	for name, issuer := range h.issuers {
+		if err := issuer.keys.Healthz(ctx); err != nil {
+			return fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// HTTP ...
+func (h *OIDCHandler) HTTP() gin.HandlerFunc {
+	return authenticateHTTP(h)
+}
+
+// Authenticate ...
+func (h *OIDCHandler) Authenticate(req *http.Request) (*Principal, error) {
+	claims, err := h.Validate(req)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return NewPrincipal(claims), nil
+}
+
+// Validate verifies the bearer token of the request and returns its claims
+func (h *OIDCHandler) Validate(req *http.Request) (map[string]interface{}, error) {
+	header := req.Header.Get("Authorization")
+
+	if len(header) < 7 || !strings.EqualFold(header[:7], "bearer ") {
+		return nil, ErrMissingToken
+	}
+
+	return h.ValidateToken(req.Context(), strings.TrimSpace(header[7:]))
+}
+
+// ValidateToken verifies the signature, issuer, audience, expiration and required claims of the token
+func (h *OIDCHandler) ValidateToken(ctx context.Context, raw string) (map[string]interface{}, error) {
+	token, err := jwt.ParseSigned(raw)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(token.Headers) != 1 || !h.algorithms[token.Headers[0].Algorithm] {
+		return nil, ErrUnsupportedAlgorithm
+	}
+
+	unverified := jwt.Claims{}
+
+	if err := token.UnsafeClaimsWithoutVerification(&unverified); err != nil {
+		return nil, err
+	}
+
+	issuer, ok := h.issuers[strings.TrimSuffix(unverified.Issuer, "/")]
+
+	if !ok {
+		return nil, ErrUntrustedIssuer
+	}
+
+	key, err := issuer.keys.Key(ctx, token.Headers[0].KeyID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	standard := jwt.Claims{}
+	claims := make(map[string]interface{})
+
+	if err := token.Claims(key, &standard, &claims); err != nil {
+		return nil, err
+	}
+
+	if standard.Expiry == nil {
+		return nil, ErrMissingExpiry
+	}
+
+	if err := standard.ValidateWithLeeway(jwt.Expected{Time: time.Now()}, h.clockSkew); err != nil {
+		return nil, err
+	}
+
+	if !audienceAllowed(standard.Audience, issuer.config.Audience) {
+		return nil, ErrInvalidAudience
+	}
+
+	for claim, expected := range h.requiredClaims {
+		if !hasClaim(claims[claim], expected) {
+			return nil, fmt.Errorf("%w %s", ErrMissingClaim, claim)
+		}
+	}
+
+	return claims, nil
+}
+
+// resolver returns the configured JWKS or discovers it from the issuer metadata
+func (h *OIDCHandler) resolver(issuer *IssuerConfig) func(context.Context) (string, error) {
+	mutex := new(sync.Mutex)
+	jwks := issuer.JWKS
+
+	return func(ctx context.Context) (string, error) {
+		mutex.Lock()
+		defer mutex.Unlock()
+
+		if jwks != "" {
+			return jwks, nil
+		}
+
+		document := new(discoveryDocument)
+		url := strings.TrimSuffix(issuer.Issuer, "/") + DiscoveryPath
+
+		if err := getJSON(ctx, h.client, url, document); err != nil {
+			return "", err
+		}
+
+		if strings.TrimSuffix(document.Issuer, "/") != strings.TrimSuffix(issuer.Issuer, "/") {
+			return "", fmt.Errorf("discovered issuer %s doesn't match %s", document.Issuer, issuer.Issuer)
+		}
+
+		jwks = document.JWKSURI
+
+		return jwks, nil
+	}
+}
+
+func findKey(keys *jose.JSONWebKeySet, kid string) *jose.JSONWebKey {
+	if keys == nil {
+		return nil
+	}
+
+	for i, key := range keys.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+
+		if kid == "" || key.KeyID == kid {
+			return &keys.Keys[i]
+		}
+	}
+
+	return nil
+}
+
+// audienceAllowed fails closed, no allowed audience rejects every token
+func audienceAllowed(audience jwt.Audience, allowed []string) bool {
+	for _, aud := range allowed {
+		if audience.Contains(aud) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasClaim checks the claim is present and, when expected isn't empty, equal to or containing it
+func hasClaim(value interface{}, expected string) bool {
+	switch v := value.(type) {
+	case nil:
+		return false
+	case []interface{}:
+		if expected == "" {
+			return len(v) > 0
+		}
+
+		for _, item := range v {
+			if fmt.Sprint(item) == expected {
+				return true
+			}
+		}
+
+		return false
+	default:
+		return expected == "" || fmt.Sprint(v) == expected
+	}
+}
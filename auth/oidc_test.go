@@ -0,0 +1,233 @@
+package auth_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raafvargas/wrapit/auth"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+type oidcProvider struct {
+	server *httptest.Server
+	keys   []jose.JSONWebKey
+	hits   int
+}
+
+func newOIDCProvider() *oidcProvider {
+	provider := new(oidcProvider)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(auth.DiscoveryPath, func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   provider.server.URL,
+			"jwks_uri": provider.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		provider.hits++
+		json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: provider.keys})
+	})
+
+	provider.server = httptest.NewServer(mux)
+
+	return provider
+}
+
+func (p *oidcProvider) addKey(kid string, key interface{}, public interface{}, alg jose.SignatureAlgorithm) jose.Signer {
+	p.keys = append(p.keys, jose.JSONWebKey{Key: public, KeyID: kid, Algorithm: string(alg), Use: "sig"})
+
+	signer, _ := jose.NewSigner(jose.SigningKey{
+		Algorithm: alg,
+		Key:       jose.JSONWebKey{Key: key, KeyID: kid},
+	}, nil)
+
+	return signer
+}
+
+func (p *oidcProvider) token(signer jose.Signer, issuer string, expiry time.Time, extra map[string]interface{}) string {
+	claims := jwt.Claims{
+		Issuer:   issuer,
+		Subject:  "user",
+		Audience: jwt.Audience{"api"},
+		Expiry:   jwt.NewNumericDate(expiry),
+		IssuedAt: jwt.NewNumericDate(time.Now()),
+	}
+
+	token, _ := jwt.Signed(signer).Claims(claims).Claims(extra).CompactSerialize()
+
+	return token
+}
+
+func oidcConfig(provider *oidcProvider) *auth.Config {
+	return &auth.Config{
+		Audience: []string{"api"},
+		Issuers: []auth.IssuerConfig{
+			{Issuer: provider.server.URL},
+			{Issuer: "https://other.example.com"},
+		},
+		ClockSkew:      30 * time.Second,
+		RequiredClaims: map[string]string{"tenant": ""},
+	}
+}
+
+func TestNewHandlerOIDC(t *testing.T) {
+	handler := auth.NewHandler(&auth.Config{Issuers: []auth.IssuerConfig{{Issuer: "https://issuer"}}})
+	assert.IsType(t, &auth.OIDCHandler{}, handler)
+}
+
+func TestOIDCAlgorithms(t *testing.T) {
+	provider := newOIDCProvider()
+	defer provider.server.Close()
+
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	signers := map[string]jose.Signer{
+		"RS256": provider.addKey("rs", rsaKey, &rsaKey.PublicKey, jose.RS256),
+		"PS256": provider.addKey("ps", rsaKey, &rsaKey.PublicKey, jose.PS256),
+		"ES256": provider.addKey("es", ecKey, &ecKey.PublicKey, jose.ES256),
+	}
+
+	handler := auth.NewOIDCHandler(oidcConfig(provider))
+
+	for alg, signer := range signers {
+		claims, err := handler.ValidateToken(context.Background(), provider.token(signer, provider.server.URL,
+			time.Now().Add(time.Hour), map[string]interface{}{"tenant": "a"}))
+
+		assert.NoError(t, err, alg)
+		assert.Equal(t, "user", claims["sub"], alg)
+	}
+}
+
+func TestOIDCInvalidTokens(t *testing.T) {
+	provider := newOIDCProvider()
+	defer provider.server.Close()
+
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	signer := provider.addKey("rs", rsaKey, &rsaKey.PublicKey, jose.RS256)
+
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	forged, _ := jose.NewSigner(jose.SigningKey{
+		Algorithm: jose.RS256,
+		Key:       jose.JSONWebKey{Key: otherKey, KeyID: "rs"},
+	}, nil)
+
+	hmac, _ := jose.NewSigner(jose.SigningKey{Algorithm: jose.HS256, Key: []byte("secret")}, nil)
+
+	handler := auth.NewOIDCHandler(oidcConfig(provider))
+	tenant := map[string]interface{}{"tenant": "a"}
+	valid := time.Now().Add(time.Hour)
+
+	tests := map[string]string{
+		"untrusted issuer": provider.token(signer, "https://evil.example.com", valid, tenant),
+		"expired":          provider.token(signer, provider.server.URL, time.Now().Add(-time.Minute), tenant),
+		"missing claim":    provider.token(signer, provider.server.URL, valid, nil),
+		"forged":           provider.token(forged, provider.server.URL, valid, tenant),
+		"symmetric":        provider.token(hmac, provider.server.URL, valid, tenant),
+		"malformed":        "token",
+	}
+
+	for name, token := range tests {
+		_, err := handler.ValidateToken(context.Background(), token)
+		assert.Error(t, err, name)
+	}
+
+	config := oidcConfig(provider)
+	config.Audience = []string{"other"}
+
+	_, err := auth.NewOIDCHandler(config).ValidateToken(context.Background(), provider.token(signer, provider.server.URL, valid, tenant))
+	assert.Equal(t, auth.ErrInvalidAudience, err)
+
+	config.Audience = nil
+
+	_, err = auth.NewOIDCHandler(config).ValidateToken(context.Background(), provider.token(signer, provider.server.URL, valid, tenant))
+	assert.Equal(t, auth.ErrInvalidAudience, err)
+
+	noExpiry, _ := jwt.Signed(signer).Claims(jwt.Claims{
+		Issuer:   provider.server.URL,
+		Subject:  "user",
+		Audience: jwt.Audience{"api"},
+	}).Claims(tenant).CompactSerialize()
+
+	_, err = handler.ValidateToken(context.Background(), noExpiry)
+	assert.Equal(t, auth.ErrMissingExpiry, err)
+}
+
+func TestOIDCClockSkew(t *testing.T) {
+	provider := newOIDCProvider()
+	defer provider.server.Close()
+
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	signer := provider.addKey("rs", rsaKey, &rsaKey.PublicKey, jose.RS256)
+
+	handler := auth.NewOIDCHandler(oidcConfig(provider))
+
+	_, err := handler.ValidateToken(context.Background(), provider.token(signer, provider.server.URL,
+		time.Now().Add(-10*time.Second), map[string]interface{}{"tenant": "a"}))
+	assert.NoError(t, err)
+}
+
+func TestOIDCKeyRotation(t *testing.T) {
+	provider := newOIDCProvider()
+	defer provider.server.Close()
+
+	first, _ := rsa.GenerateKey(rand.Reader, 2048)
+	signer := provider.addKey("first", first, &first.PublicKey, jose.RS256)
+
+	config := oidcConfig(provider)
+	config.JWKSCache.MinRefreshInterval = time.Nanosecond
+
+	handler := auth.NewOIDCHandler(config)
+	tenant := map[string]interface{}{"tenant": "a"}
+
+	_, err := handler.ValidateToken(context.Background(), provider.token(signer, provider.server.URL, time.Now().Add(time.Hour), tenant))
+	assert.NoError(t, err)
+
+	second, _ := rsa.GenerateKey(rand.Reader, 2048)
+	signer = provider.addKey("second", second, &second.PublicKey, jose.RS256)
+
+	_, err = handler.ValidateToken(context.Background(), provider.token(signer, provider.server.URL, time.Now().Add(time.Hour), tenant))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, provider.hits)
+}
+
+func TestOIDCHandlerHTTP(t *testing.T) {
+	provider := newOIDCProvider()
+	defer provider.server.Close()
+
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	signer := provider.addKey("rs", rsaKey, &rsaKey.PublicKey, jose.RS256)
+
+	engine := gin.New()
+	engine.GET("me", auth.NewOIDCHandler(oidcConfig(provider)).HTTP(), func(ctx *gin.Context) {
+		principal, _ := auth.FromContext(ctx.Request.Context())
+		ctx.String(http.StatusOK, principal.Subject)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+provider.token(signer, provider.server.URL,
+		time.Now().Add(time.Hour), map[string]interface{}{"tenant": "a"}))
+
+	res := httptest.NewRecorder()
+	engine.ServeHTTP(res, req)
+
+	assert.Equal(t, http.StatusOK, res.Code)
+	assert.Equal(t, "user", res.Body.String())
+
+	res = httptest.NewRecorder()
+	engine.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/me", nil))
+
+	assert.Equal(t, http.StatusUnauthorized, res.Code)
+}
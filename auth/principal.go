@@ -0,0 +1,137 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	// PrincipalKey is the gin context key holding the authenticated principal
+	PrincipalKey = "principal"
+
+	// PrincipalHeader carries the signed principal across message brokers
+	PrincipalHeader = "x-principal"
+
+	// TenantClaim ...
+	TenantClaim = "tenant"
+)
+
+type principalKey struct{}
+
+// Principal is the authenticated caller of a request, message or rpc
+type Principal struct {
+	Subject   string                 `json:"sub"`
+	Issuer    string                 `json:"iss,omitempty"`
+	Audience  []string               `json:"aud,omitempty"`
+	Scopes    []string               `json:"scopes,omitempty"`
+	Tenant    string                 `json:"tenant,omitempty"`
+	ExpiresAt time.Time              `json:"exp,omitempty"`
+	Claims    map[string]interface{} `json:"claims,omitempty"`
+}
+
+// TokenValidator validates raw bearer tokens returning their claims
+type TokenValidator interface {
+	ValidateToken(ctx context.Context, raw string) (map[string]interface{}, error)
+}
+
+// NewPrincipal reads the standard claims, the default scope claims and the tenant claim
+func NewPrincipal(claims map[string]interface{}) *Principal {
+	principal := &Principal{
+		Claims: claims,
+	}
+
+	if principal.Claims == nil {
+		principal.Claims = make(map[string]interface{})
+	}
+
+	principal.Subject, _ = claims["sub"].(string)
+	principal.Issuer, _ = claims["iss"].(string)
+	principal.Tenant, _ = claims[TenantClaim].(string)
+	principal.Audience = claimValues(claims["aud"])
+
+	for _, claim := range DefaultScopeClaims {
+		principal.Scopes = append(principal.Scopes, claimValues(claims[claim])...)
+	}
+
+	switch exp := claims["exp"].(type) {
+	case float64:
+		principal.ExpiresAt = time.Unix(int64(exp), 0)
+	case int64:
+		principal.ExpiresAt = time.Unix(exp, 0)
+	case json.Number:
+		if seconds, err := exp.Int64(); err == nil {
+			principal.ExpiresAt = time.Unix(seconds, 0)
+		}
+	}
+
+	return principal
+}
+
+// HasScope ...
+func (p *Principal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Decode decodes the raw claims into dest, a struct with json tags
+func (p *Principal) Decode(dest interface{}) error {
+	data, err := json.Marshal(p.Claims)
+
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, dest)
+}
+
+// NewContext ...
+func NewContext(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// FromContext returns the principal of HTTP requests, gRPC calls and AMQP messages
+func FromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
+
+// SetPrincipal stores the principal in the gin context and the request context
+func SetPrincipal(ctx *gin.Context, principal *Principal) {
+	ctx.Set(PrincipalKey, principal)
+
+	if ctx.Request != nil {
+		ctx.Request = ctx.Request.WithContext(NewContext(ctx.Request.Context(), principal))
+	}
+}
+
+// GetPrincipal ...
+func GetPrincipal(ctx *gin.Context) (*Principal, bool) {
+	value, exists := ctx.Get(PrincipalKey)
+
+	if !exists {
+		return nil, false
+	}
+
+	principal, ok := value.(*Principal)
+
+	return principal, ok && principal != nil
+}
+
+// claim reads the claim from the principal, falling back to a gin context key
+// for handlers setting claims directly
+func claim(ctx *gin.Context, name string) (interface{}, bool) {
+	if principal, ok := GetPrincipal(ctx); ok {
+		value, exists := principal.Claims[name]
+		return value, exists
+	}
+
+	return ctx.Get(name)
+}
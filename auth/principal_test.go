@@ -0,0 +1,93 @@
+package auth_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/raafvargas/wrapit/auth"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewPrincipal(t *testing.T) {
+	expiry := time.Now().Add(time.Hour).Truncate(time.Second)
+
+	principal := auth.NewPrincipal(map[string]interface{}{
+		"sub":         "user",
+		"iss":         "https://issuer",
+		"aud":         []interface{}{"api", "web"},
+		"scope":       "read write",
+		"permissions": []interface{}{"admin"},
+		"tenant":      "acme",
+		"exp":         float64(expiry.Unix()),
+	})
+
+	assert.Equal(t, "user", principal.Subject)
+	assert.Equal(t, "https://issuer", principal.Issuer)
+	assert.Equal(t, []string{"api", "web"}, principal.Audience)
+	assert.ElementsMatch(t, []string{"read", "write", "admin"}, principal.Scopes)
+	assert.Equal(t, "acme", principal.Tenant)
+	assert.True(t, expiry.Equal(principal.ExpiresAt))
+	assert.True(t, principal.HasScope("write"))
+	assert.False(t, principal.HasScope("delete"))
+}
+
+func TestPrincipalDecode(t *testing.T) {
+	principal := auth.NewPrincipal(map[string]interface{}{
+		"sub":   "user",
+		"email": "user@example.com",
+		"plan":  map[string]interface{}{"name": "pro"},
+	})
+
+	claims := struct {
+		Email string `json:"email"`
+		Plan  struct {
+			Name string `json:"name"`
+		} `json:"plan"`
+	}{}
+
+	assert.NoError(t, principal.Decode(&claims))
+	assert.Equal(t, "user@example.com", claims.Email)
+	assert.Equal(t, "pro", claims.Plan.Name)
+}
+
+func TestPrincipalContext(t *testing.T) {
+	_, ok := auth.FromContext(context.Background())
+	assert.False(t, ok)
+
+	ctx := auth.NewContext(context.Background(), &auth.Principal{Subject: "user"})
+
+	principal, ok := auth.FromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "user", principal.Subject)
+}
+
+func TestSetPrincipal(t *testing.T) {
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+	ctx.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+
+	_, ok := auth.GetPrincipal(ctx)
+	assert.False(t, ok)
+
+	auth.SetPrincipal(ctx, &auth.Principal{Subject: "user"})
+
+	principal, ok := auth.GetPrincipal(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "user", principal.Subject)
+
+	principal, ok = auth.FromContext(ctx.Request.Context())
+	assert.True(t, ok)
+	assert.Equal(t, "user", principal.Subject)
+}
+
+func TestAuthorizerPrincipalClaims(t *testing.T) {
+	ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+
+	auth.SetPrincipal(ctx, auth.NewPrincipal(map[string]interface{}{"scope": "read"}))
+	ctx.Set("scope", "write")
+
+	assert.Equal(t, auth.Scopes{"read": true}, auth.DefaultAuthorizer.Scopes(ctx))
+}
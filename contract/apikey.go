@@ -0,0 +1,27 @@
+package contract
+
+import (
+	"context"
+	"time"
+)
+
+// APIKey is a stored key. Only the SHA-256 hash of the key is kept, the prefix
+// identifies it in storage and logs.
+type APIKey struct {
+	ID         string    `bson:"_id" yaml:"id"`
+	Prefix     string    `bson:"prefix" yaml:"prefix"`
+	Hash       string    `bson:"hash" yaml:"hash"`
+	Subject    string    `bson:"subject" yaml:"subject"`
+	Tenant     string    `bson:"tenant,omitempty" yaml:"tenant"`
+	Scopes     []string  `bson:"scopes" yaml:"scopes"`
+	ExpiresAt  time.Time `bson:"expires_at,omitempty" yaml:"expires_at"`
+	LastUsedAt time.Time `bson:"last_used_at,omitempty" yaml:"-"`
+}
+
+// APIKeyStore finds keys by prefix and tracks their usage
+type APIKeyStore interface {
+	// Find returns a nil key when no key has the prefix
+	Find(ctx context.Context, prefix string) (*APIKey, error)
+	// Touch sets the last used time of the key
+	Touch(ctx context.Context, id string, at time.Time) error
+}
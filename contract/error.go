@@ -10,8 +10,18 @@ import (
 
 // Error ...
 type Error struct {
-	Code     int      `json:"code"`
-	Messages []string `json:"messages"`
+	Code      int          `json:"code"`
+	Messages  []string     `json:"messages"`
+	Details   []FieldError `json:"details,omitempty"`
+	RequestID string       `json:"request_id,omitempty"`
+}
+
+// FieldError ...
+type FieldError struct {
+	Field string      `json:"field"`
+	Tag   string      `json:"tag"`
+	Param string      `json:"param,omitempty"`
+	Value interface{} `json:"value,omitempty"`
 }
 
 // NewError  ...
@@ -42,7 +52,20 @@ func FromValidationError(e error) *Error {
 	message := "invalid value for field %s"
 
 	for _, e := range validationErrors {
-		err.Messages = append(err.Messages, fmt.Sprintf(message, e.Field()))
+		field := e.Field()
+
+		// the namespace is prefixed by the root struct name
+		if i := strings.Index(e.Namespace(), "."); i >= 0 {
+			field = e.Namespace()[i+1:]
+		}
+
+		err.Messages = append(err.Messages, fmt.Sprintf(message, field))
+		err.Details = append(err.Details, FieldError{
+			Field: field,
+			Tag:   e.Tag(),
+			Param: e.Param(),
+			Value: e.Value(),
+		})
 	}
 
 	return err
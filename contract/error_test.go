@@ -34,6 +34,10 @@ func TestFromValidationError(t *testing.T) {
 
 	e = contract.FromValidationError(err)
 	assert.Equal(t, http.StatusUnprocessableEntity, e.Code)
+	assert.Equal(t, "Value", e.Details[0].Field)
+	assert.Equal(t, "gt", e.Details[0].Tag)
+	assert.Equal(t, "0", e.Details[0].Param)
+	assert.Equal(t, -1, e.Details[0].Value)
 }
 
 func TestBusinessError(t *testing.T) {
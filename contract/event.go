@@ -0,0 +1,17 @@
+package contract
+
+import "context"
+
+type eventTypeContextKey struct{}
+
+// ContextWithEventType sets the type of the event published with ctx, such as the
+// cloud event type of rabbitmq.Producer
+func ContextWithEventType(ctx context.Context, eventType string) context.Context {
+	return context.WithValue(ctx, eventTypeContextKey{}, eventType)
+}
+
+// EventTypeFromContext ...
+func EventTypeFromContext(ctx context.Context) (string, bool) {
+	eventType, ok := ctx.Value(eventTypeContextKey{}).(string)
+	return eventType, ok && eventType != ""
+}
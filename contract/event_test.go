@@ -0,0 +1,18 @@
+package contract_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/raafvargas/wrapit/contract"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventTypeFromContext(t *testing.T) {
+	_, ok := contract.EventTypeFromContext(context.Background())
+	assert.False(t, ok)
+
+	eventType, ok := contract.EventTypeFromContext(contract.ContextWithEventType(context.Background(), "push"))
+	assert.True(t, ok)
+	assert.Equal(t, "push", eventType)
+}
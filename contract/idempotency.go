@@ -0,0 +1,28 @@
+package contract
+
+import (
+	"context"
+	"net/http"
+	"time"
+)
+
+// IdempotencyRecord ...
+type IdempotencyRecord struct {
+	Key         string      `bson:"_id"`
+	Fingerprint string      `bson:"fingerprint"`
+	Completed   bool        `bson:"completed"`
+	Status      int         `bson:"status"`
+	Headers     http.Header `bson:"headers"`
+	Body        []byte      `bson:"body"`
+	ExpiresAt   time.Time   `bson:"expires_at"`
+}
+
+// IdempotencyStore ...
+type IdempotencyStore interface {
+	// Begin reserves the key. When an unexpired record exists it returns the stored record and false.
+	Begin(ctx context.Context, record *IdempotencyRecord) (*IdempotencyRecord, bool, error)
+	// Complete stores the response of a reserved key
+	Complete(ctx context.Context, record *IdempotencyRecord) error
+	// Release removes a reserved key so the request can be retried
+	Release(ctx context.Context, key string) error
+}
@@ -0,0 +1,67 @@
+package contract
+
+// SortField ...
+type SortField struct {
+	Field      string `json:"field"`
+	Descending bool   `json:"descending"`
+}
+
+// ListRequest holds the pagination, sorting and filtering params of a list endpoint.
+// Page is used for page-based pagination and Cursor for cursor-based pagination.
+type ListRequest struct {
+	Page    int               `json:"page,omitempty"`
+	Limit   int               `json:"limit"`
+	Cursor  string            `json:"cursor,omitempty"`
+	Sort    []SortField       `json:"sort,omitempty"`
+	Filters map[string]string `json:"filters,omitempty"`
+}
+
+// Offset ...
+func (r *ListRequest) Offset() int {
+	if r.Page <= 1 {
+		return 0
+	}
+
+	return (r.Page - 1) * r.Limit
+}
+
+// PageResponse ...
+type PageResponse struct {
+	Items      interface{} `json:"items"`
+	Page       int         `json:"page"`
+	Limit      int         `json:"limit"`
+	Total      int64       `json:"total"`
+	TotalPages int64       `json:"total_pages"`
+}
+
+// CursorResponse ...
+type CursorResponse struct {
+	Items      interface{} `json:"items"`
+	Limit      int         `json:"limit"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+// NewPageResponse ...
+func NewPageResponse(items interface{}, request *ListRequest, total int64) *PageResponse {
+	response := &PageResponse{
+		Items: items,
+		Page:  request.Page,
+		Limit: request.Limit,
+		Total: total,
+	}
+
+	if request.Limit > 0 {
+		response.TotalPages = (total + int64(request.Limit) - 1) / int64(request.Limit)
+	}
+
+	return response
+}
+
+// NewCursorResponse ...
+func NewCursorResponse(items interface{}, request *ListRequest, nextCursor string) *CursorResponse {
+	return &CursorResponse{
+		Items:      items,
+		Limit:      request.Limit,
+		NextCursor: nextCursor,
+	}
+}
@@ -0,0 +1,28 @@
+package contract_test
+
+import (
+	"testing"
+
+	"github.com/raafvargas/wrapit/contract"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestListRequestOffset(t *testing.T) {
+	assert.Equal(t, 0, (&contract.ListRequest{Page: 0, Limit: 10}).Offset())
+	assert.Equal(t, 0, (&contract.ListRequest{Page: 1, Limit: 10}).Offset())
+	assert.Equal(t, 20, (&contract.ListRequest{Page: 3, Limit: 10}).Offset())
+}
+
+func TestNewPageResponse(t *testing.T) {
+	response := contract.NewPageResponse([]int{1}, &contract.ListRequest{Page: 1, Limit: 10}, 21)
+
+	assert.Equal(t, int64(21), response.Total)
+	assert.Equal(t, int64(3), response.TotalPages)
+}
+
+func TestNewCursorResponse(t *testing.T) {
+	response := contract.NewCursorResponse([]int{1}, &contract.ListRequest{Limit: 10}, "next")
+
+	assert.Equal(t, "next", response.NextCursor)
+	assert.Equal(t, 10, response.Limit)
+}
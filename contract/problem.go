@@ -0,0 +1,99 @@
+package contract
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ProblemContentType ...
+	ProblemContentType = "application/problem+json"
+
+	// DefaultProblemType ...
+	DefaultProblemType = "about:blank"
+)
+
+// Problem is a RFC 7807 problem details object
+type Problem struct {
+	Type       string                 `json:"type"`
+	Title      string                 `json:"title"`
+	Status     int                    `json:"status"`
+	Detail     string                 `json:"detail,omitempty"`
+	Instance   string                 `json:"instance,omitempty"`
+	Extensions map[string]interface{} `json:"-"`
+}
+
+// NewProblem ...
+func NewProblem(status int, detail string) *Problem {
+	return &Problem{
+		Type:       DefaultProblemType,
+		Title:      http.StatusText(status),
+		Status:     status,
+		Detail:     detail,
+		Extensions: make(map[string]interface{}),
+	}
+}
+
+// Problem converts the error into a problem details object
+func (e *Error) Problem() *Problem {
+	status := e.Code
+
+	if status == 0 {
+		status = http.StatusBadRequest
+	}
+
+	problem := NewProblem(status, strings.Join(e.Messages, "; "))
+
+	if len(e.Details) > 0 {
+		problem.Extensions["errors"] = e.Details
+	}
+
+	return problem
+}
+
+// MarshalJSON writes the extension members alongside the standard ones
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	members := make(map[string]interface{}, len(p.Extensions)+5)
+
+	for k, v := range p.Extensions {
+		members[k] = v
+	}
+
+	members["type"] = p.Type
+	members["title"] = p.Title
+	members["status"] = p.Status
+
+	if p.Detail != "" {
+		members["detail"] = p.Detail
+	}
+
+	if p.Instance != "" {
+		members["instance"] = p.Instance
+	}
+
+	return json.Marshal(members)
+}
+
+// UnmarshalJSON ...
+func (p *Problem) UnmarshalJSON(data []byte) error {
+	type standard Problem
+
+	if err := json.Unmarshal(data, (*standard)(p)); err != nil {
+		return err
+	}
+
+	members := make(map[string]interface{})
+
+	if err := json.Unmarshal(data, &members); err != nil {
+		return err
+	}
+
+	for _, key := range []string{"type", "title", "status", "detail", "instance"} {
+		delete(members, key)
+	}
+
+	p.Extensions = members
+
+	return nil
+}
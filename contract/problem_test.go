@@ -0,0 +1,38 @@
+package contract_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/raafvargas/wrapit/contract"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProblem(t *testing.T) {
+	problem := contract.NewProblem(http.StatusNotFound, "not found")
+	problem.Instance = "/users/1"
+	problem.Extensions["trace_id"] = "trace"
+
+	data, err := json.Marshal(problem)
+	assert.NoError(t, err)
+
+	decoded := new(contract.Problem)
+	assert.NoError(t, json.Unmarshal(data, decoded))
+	assert.Equal(t, contract.DefaultProblemType, decoded.Type)
+	assert.Equal(t, "Not Found", decoded.Title)
+	assert.Equal(t, http.StatusNotFound, decoded.Status)
+	assert.Equal(t, "/users/1", decoded.Instance)
+	assert.Equal(t, "trace", decoded.Extensions["trace_id"])
+	assert.NotContains(t, decoded.Extensions, "status")
+}
+
+func TestErrorProblem(t *testing.T) {
+	problem := contract.BusinessError("a", "b").Problem()
+
+	assert.Equal(t, http.StatusConflict, problem.Status)
+	assert.Equal(t, "a; b", problem.Detail)
+
+	problem = contract.NewError(0, "bad").Problem()
+	assert.Equal(t, http.StatusBadRequest, problem.Status)
+}
@@ -0,0 +1,51 @@
+package contract
+
+import (
+	"context"
+	"time"
+)
+
+// Rate is a token bucket refilled with Limit tokens per second up to Burst tokens
+type Rate struct {
+	Limit float64 `yaml:"limit"`
+	Burst int     `yaml:"burst"`
+}
+
+// PerMinute ...
+func PerMinute(requests int) Rate {
+	return Rate{Limit: float64(requests) / 60, Burst: requests}
+}
+
+// RateLimitResult ...
+type RateLimitResult struct {
+	Allowed    bool
+	Remaining  int
+	Reset      time.Duration
+	RetryAfter time.Duration
+}
+
+// Result builds the result of a take leaving tokens in the bucket, shared by the stores.
+// Buckets without a Limit never refill, their results have no Reset nor RetryAfter.
+func (r Rate) Result(tokens float64, allowed bool) RateLimitResult {
+	result := RateLimitResult{
+		Allowed:   allowed,
+		Remaining: int(tokens),
+	}
+
+	if r.Limit <= 0 {
+		return result
+	}
+
+	result.Reset = time.Duration((float64(r.Burst) - tokens) / r.Limit * float64(time.Second))
+
+	if !allowed {
+		result.RetryAfter = time.Duration((1 - tokens) / r.Limit * float64(time.Second))
+	}
+
+	return result
+}
+
+// RateLimitStore takes a token from the bucket identified by key
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, rate Rate) (RateLimitResult, error)
+}
@@ -0,0 +1,27 @@
+package contract_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/raafvargas/wrapit/contract"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateResult(t *testing.T) {
+	rate := contract.Rate{Limit: 2, Burst: 4}
+
+	assert.Equal(t, contract.RateLimitResult{
+		Allowed:   true,
+		Remaining: 1,
+		Reset:     1500 * time.Millisecond,
+	}, rate.Result(1, true))
+
+	assert.Equal(t, contract.RateLimitResult{
+		Remaining:  0,
+		Reset:      1750 * time.Millisecond,
+		RetryAfter: 250 * time.Millisecond,
+	}, rate.Result(0.5, false))
+
+	assert.Equal(t, contract.RateLimitResult{}, contract.Rate{Burst: 1}.Result(0, false))
+}
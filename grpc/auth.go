@@ -0,0 +1,68 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"github.com/raafvargas/wrapit/auth"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// AuthorizationMetadataKey ...
+const AuthorizationMetadataKey = "authorization"
+
+// AuthUnaryServerInterceptor validates the bearer token of the call, storing the
+// principal for auth.FromContext
+func AuthUnaryServerInterceptor(validator auth.TokenValidator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := authenticate(ctx, validator)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// AuthStreamServerInterceptor ...
+func AuthStreamServerInterceptor(validator auth.TokenValidator) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authenticate(stream.Context(), validator)
+
+		if err != nil {
+			return err
+		}
+
+		wrapped := grpc_middleware.WrapServerStream(stream)
+		wrapped.WrappedContext = ctx
+
+		return handler(srv, wrapped)
+	}
+}
+
+func authenticate(ctx context.Context, validator auth.TokenValidator) (context.Context, error) {
+	token := ""
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(AuthorizationMetadataKey); len(values) > 0 {
+			token = values[0]
+		}
+	}
+
+	if len(token) < 7 || !strings.EqualFold(token[:7], "bearer ") {
+		return nil, status.Error(codes.Unauthenticated, auth.ErrMissingToken.Error())
+	}
+
+	claims, err := validator.ValidateToken(ctx, strings.TrimSpace(token[7:]))
+
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	return auth.NewContext(ctx, auth.NewPrincipal(claims)), nil
+}
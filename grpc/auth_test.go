@@ -0,0 +1,59 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/raafvargas/wrapit/auth"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+type tokenValidator map[string]string
+
+func (v tokenValidator) ValidateToken(ctx context.Context, raw string) (map[string]interface{}, error) {
+	subject, ok := v[raw]
+
+	if !ok {
+		return nil, errors.New("invalid token")
+	}
+
+	return map[string]interface{}{"sub": subject}, nil
+}
+
+func TestAuthUnaryServerInterceptor(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs(AuthorizationMetadataKey, "Bearer token"))
+
+	_, err := AuthUnaryServerInterceptor(tokenValidator{"token": "user"})(ctx, nil, &grpc.UnaryServerInfo{},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			principal, ok := auth.FromContext(ctx)
+			assert.True(t, ok)
+			assert.Equal(t, "user", principal.Subject)
+			return nil, nil
+		})
+
+	assert.NoError(t, err)
+}
+
+func TestAuthUnaryServerInterceptorUnauthenticated(t *testing.T) {
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		t.Fatal("handler called")
+		return nil, nil
+	}
+
+	contexts := []context.Context{
+		context.Background(),
+		metadata.NewIncomingContext(context.Background(), metadata.Pairs(AuthorizationMetadataKey, "Basic token")),
+		metadata.NewIncomingContext(context.Background(), metadata.Pairs(AuthorizationMetadataKey, "Bearer invalid")),
+	}
+
+	for _, ctx := range contexts {
+		_, err := AuthUnaryServerInterceptor(tokenValidator{"token": "user"})(ctx, nil, &grpc.UnaryServerInfo{}, handler)
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	}
+}
@@ -39,11 +39,13 @@ func NewClient(opts ...GRPClientOption) (*grpc.ClientConn, error) {
 
 		grpc.WithChainUnaryInterceptor(
 			grpc_retry.UnaryClientInterceptor(),
+			RequestIDUnaryClientInterceptor(),
 			grpctrace.UnaryClientInterceptor(config.tracer),
 		),
 
 		grpc.WithChainStreamInterceptor(
 			grpc_retry.StreamClientInterceptor(),
+			RequestIDStreamClientInterceptor(),
 			grpctrace.StreamClientInterceptor(config.tracer),
 		),
 	)
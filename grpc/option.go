@@ -3,6 +3,7 @@ package grpc
 import (
 	"os"
 
+	"github.com/raafvargas/wrapit/auth"
 	"github.com/sirupsen/logrus"
 	"go.opentelemetry.io/otel/api/trace"
 )
@@ -47,8 +48,22 @@ func WithServerShutdown(shutdown chan os.Signal) GRPCServerOption {
 	}
 }
 
+// WithServerSignals sets the signals stopping the server. No signals leaves it to the Run context.
+func WithServerSignals(signals ...os.Signal) GRPCServerOption {
+	return func(s *GRPCServer) {
+		s.signals = signals
+	}
+}
+
 func WithServerService(service GRPCService) GRPCServerOption {
 	return func(s *GRPCServer) {
 		s.services = append(s.services, service)
 	}
 }
+
+// WithServerAuth validates the bearer token of every call, rejecting unauthenticated calls
+func WithServerAuth(validator auth.TokenValidator) GRPCServerOption {
+	return func(s *GRPCServer) {
+		s.auth = validator
+	}
+}
@@ -64,3 +64,19 @@ func TestWithClientTracer(t *testing.T) {
 
 	assert.NotNil(t, grpcClient.tracer)
 }
+
+func TestWithServerSignals(t *testing.T) {
+	grpcServer := &GRPCServer{}
+
+	WithServerSignals(os.Interrupt)(grpcServer)
+
+	assert.Equal(t, []os.Signal{os.Interrupt}, grpcServer.signals)
+}
+
+func TestWithServerAuth(t *testing.T) {
+	grpcServer := &GRPCServer{}
+
+	WithServerAuth(tokenValidator{})(grpcServer)
+
+	assert.NotNil(t, grpcServer.auth)
+}
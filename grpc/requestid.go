@@ -0,0 +1,71 @@
+package grpc
+
+import (
+	"context"
+
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"github.com/grpc-ecosystem/go-grpc-middleware/logging/logrus/ctxlogrus"
+	"github.com/raafvargas/wrapit/requestid"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// RequestIDUnaryServerInterceptor reads the request id metadata or generates a new one
+func RequestIDUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(incomingRequestID(ctx), req)
+	}
+}
+
+// RequestIDStreamServerInterceptor ...
+func RequestIDStreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		wrapped := grpc_middleware.WrapServerStream(stream)
+		wrapped.WrappedContext = incomingRequestID(stream.Context())
+
+		return handler(srv, wrapped)
+	}
+}
+
+// RequestIDUnaryClientInterceptor forwards the request id of the context
+func RequestIDUnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		return invoker(outgoingRequestID(ctx), method, req, reply, cc, opts...)
+	}
+}
+
+// RequestIDStreamClientInterceptor ...
+func RequestIDStreamClientInterceptor() grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		return streamer(outgoingRequestID(ctx), desc, cc, method, opts...)
+	}
+}
+
+func incomingRequestID(ctx context.Context) context.Context {
+	id := ""
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(requestid.MetadataKey); len(values) > 0 {
+			id = values[0]
+		}
+	}
+
+	if !requestid.Valid(id) {
+		id = requestid.New()
+	}
+
+	grpc.SetHeader(ctx, metadata.Pairs(requestid.MetadataKey, id))
+	ctxlogrus.AddFields(ctx, map[string]interface{}{requestid.LogField: id})
+
+	return requestid.NewContext(ctx, id)
+}
+
+func outgoingRequestID(ctx context.Context) context.Context {
+	id := requestid.FromContext(ctx)
+
+	if id == "" {
+		return ctx
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, requestid.MetadataKey, id)
+}
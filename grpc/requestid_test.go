@@ -0,0 +1,62 @@
+package grpc
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/raafvargas/wrapit/requestid"
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestRequestIDUnaryServerInterceptor(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs(requestid.MetadataKey, "id"))
+
+	_, err := RequestIDUnaryServerInterceptor()(ctx, nil, &grpc.UnaryServerInfo{},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			assert.Equal(t, "id", requestid.FromContext(ctx))
+			return nil, nil
+		})
+
+	assert.NoError(t, err)
+}
+
+func TestRequestIDUnaryServerInterceptorGenerated(t *testing.T) {
+	_, err := RequestIDUnaryServerInterceptor()(context.Background(), nil, &grpc.UnaryServerInfo{},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			assert.NotEmpty(t, requestid.FromContext(ctx))
+			return nil, nil
+		})
+
+	assert.NoError(t, err)
+}
+
+func TestRequestIDUnaryServerInterceptorInvalid(t *testing.T) {
+	ctx := metadata.NewIncomingContext(context.Background(),
+		metadata.Pairs(requestid.MetadataKey, strings.Repeat("a", requestid.MaxLength+1)))
+
+	_, err := RequestIDUnaryServerInterceptor()(ctx, nil, &grpc.UnaryServerInfo{},
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			assert.True(t, requestid.Valid(requestid.FromContext(ctx)))
+			assert.Len(t, requestid.FromContext(ctx), 36)
+			return nil, nil
+		})
+
+	assert.NoError(t, err)
+}
+
+func TestRequestIDUnaryClientInterceptor(t *testing.T) {
+	ctx := requestid.NewContext(context.Background(), "id")
+
+	err := RequestIDUnaryClientInterceptor()(ctx, "method", nil, nil, nil,
+		func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+			md, _ := metadata.FromOutgoingContext(ctx)
+			assert.Equal(t, []string{"id"}, md.Get(requestid.MetadataKey))
+			return nil
+		})
+
+	assert.NoError(t, err)
+}
@@ -9,6 +9,7 @@ import (
 
 	grpc_logrus "github.com/grpc-ecosystem/go-grpc-middleware/logging/logrus"
 	grpc_recovery "github.com/grpc-ecosystem/go-grpc-middleware/recovery"
+	"github.com/raafvargas/wrapit/auth"
 	"github.com/sirupsen/logrus"
 	grpctrace "go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc"
 	"go.opentelemetry.io/otel/api/global"
@@ -28,7 +29,9 @@ type GRPCServer struct {
 	services []GRPCService
 	tracer   trace.Tracer
 	shutdown chan os.Signal
+	signals  []os.Signal
 	logger   *logrus.Logger
+	auth     auth.TokenValidator
 }
 
 func NewServer(opts ...GRPCServerOption) *GRPCServer {
@@ -49,9 +52,6 @@ func NewServer(opts ...GRPCServerOption) *GRPCServer {
 }
 
 func (s *GRPCServer) Run(ctx context.Context) error {
-	ctx, cancel := context.WithCancel(ctx)
-	defer cancel()
-
 	ln, err := net.Listen("tcp", s.host)
 
 	if err != nil {
@@ -61,22 +61,33 @@ func (s *GRPCServer) Run(ctx context.Context) error {
 	s.listener = ln
 	defer s.listener.Close()
 
-	signal.Notify(s.shutdown, os.Interrupt)
+	if len(s.signals) > 0 {
+		signal.Notify(s.shutdown, s.signals...)
+		defer signal.Stop(s.shutdown)
+	}
+
+	stopped := make(chan struct{})
+	defer close(stopped)
 
 	go func() {
 		select {
 		case sig := <-s.shutdown:
 			s.logger.WithField("sig", sig.String()).
 				Info("starting server graceful shutdown")
-			s.grpc.GracefulStop()
 		case <-ctx.Done():
+			s.logger.Info("context done, starting server graceful shutdown")
+		case <-stopped:
+			return
 		}
+
+		s.grpc.GracefulStop()
 	}()
 
 	s.logger.WithField("addr", s.listener.Addr().String()).
 		Info("starting grpc server")
 
-	if err := s.grpc.Serve(s.listener); err != nil {
+	// stopping before Serve starts makes it return ErrServerStopped
+	if err := s.grpc.Serve(s.listener); err != nil && err != grpc.ErrServerStopped {
 		return err
 	}
 
@@ -86,18 +97,29 @@ func (s *GRPCServer) Run(ctx context.Context) error {
 func (s *GRPCServer) creteServer() {
 	entry := logrus.NewEntry(s.logger)
 
+	unary := []grpc.UnaryServerInterceptor{
+		grpc_recovery.UnaryServerInterceptor(),
+		grpc_logrus.UnaryServerInterceptor(entry),
+		RequestIDUnaryServerInterceptor(),
+		grpctrace.UnaryServerInterceptor(s.tracer),
+	}
+
+	stream := []grpc.StreamServerInterceptor{
+		grpc_recovery.StreamServerInterceptor(),
+		grpc_logrus.StreamServerInterceptor(entry),
+		RequestIDStreamServerInterceptor(),
+		grpctrace.StreamServerInterceptor(s.tracer),
+	}
+
+	if s.auth != nil {
+		unary = append(unary, AuthUnaryServerInterceptor(s.auth))
+		stream = append(stream, AuthStreamServerInterceptor(s.auth))
+	}
+
 	s.grpc = grpc.NewServer(
-		grpc.ChainUnaryInterceptor(
-			grpc_recovery.UnaryServerInterceptor(),
-			grpc_logrus.UnaryServerInterceptor(entry),
-			grpctrace.UnaryServerInterceptor(s.tracer),
-		),
-
-		grpc.ChainStreamInterceptor(
-			grpc_recovery.StreamServerInterceptor(),
-			grpc_logrus.StreamServerInterceptor(entry),
-			grpctrace.StreamServerInterceptor(s.tracer),
-		),
+		grpc.ChainUnaryInterceptor(unary...),
+
+		grpc.ChainStreamInterceptor(stream...),
 
 		grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
 			MinTime:             time.Second * 10,
@@ -116,4 +138,5 @@ func (s *GRPCServer) defaults() {
 	s.tracer = global.Tracer("grpc")
 	s.services = []GRPCService{}
 	s.shutdown = make(chan os.Signal)
+	s.signals = []os.Signal{os.Interrupt}
 }
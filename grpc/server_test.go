@@ -36,3 +36,21 @@ func TestServerInvalidHost(t *testing.T) {
 
 	assert.Error(t, err)
 }
+
+func TestServerContextShutdown(t *testing.T) {
+	errCh := make(chan error)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	grpcServer := NewServer(
+		WithServerHost(":0"),
+		WithServerSignals(),
+	)
+
+	go func() {
+		errCh <- grpcServer.Run(ctx)
+	}()
+
+	cancel()
+
+	assert.NoError(t, <-errCh)
+}
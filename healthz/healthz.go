@@ -30,6 +30,15 @@ func WithMongo(client *mongo.Client) HealtzOption {
 	}
 }
 
+// WithCheck adds a custom check such as auth.JWKSCache.Healthz
+func WithCheck(check func(context.Context) error) HealtzOption {
+	return func(h *Healthz) {
+		h.checks = append(h.checks, func(ctx context.Context, healthz *Healthz) error {
+			return check(ctx)
+		})
+	}
+}
+
 // NewHealthz ...
 func NewHealthz(options ...HealtzOption) *Healthz {
 	h := new(Healthz)
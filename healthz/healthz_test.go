@@ -2,6 +2,7 @@ package healthz_test
 
 import (
 	"context"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -69,3 +70,19 @@ func TestHealthzInvalidMongo(t *testing.T) {
 
 	assert.Equal(t, http.StatusInternalServerError, res.Code)
 }
+
+func TestHealthzWithCheck(t *testing.T) {
+	res := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(res)
+	ctx.Request = httptest.NewRequest("GET", "/healthz", nil)
+
+	handler := healthz.HTTPHealthz(
+		healthz.WithCheck(func(context.Context) error {
+			return errors.New("stale")
+		}),
+	)
+
+	handler(ctx)
+
+	assert.Equal(t, http.StatusInternalServerError, res.Code)
+}
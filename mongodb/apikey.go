@@ -0,0 +1,72 @@
+package mongodb
+
+import (
+	"context"
+	"time"
+
+	"github.com/raafvargas/wrapit/contract"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// APIKeyStore keeps the API keys in a collection indexed by prefix
+type APIKeyStore struct {
+	Collection *mongo.Collection
+}
+
+// NewAPIKeyStore ...
+func NewAPIKeyStore(collection *mongo.Collection) *APIKeyStore {
+	return &APIKeyStore{
+		Collection: collection,
+	}
+}
+
+// EnsureIndexes creates the unique prefix index
+func (s *APIKeyStore) EnsureIndexes(ctx context.Context) error {
+	_, err := s.Collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.M{"prefix": 1},
+		Options: options.Index().SetUnique(true),
+	})
+
+	return err
+}
+
+// Find returns a nil key when no key has the prefix
+func (s *APIKeyStore) Find(ctx context.Context, prefix string) (*contract.APIKey, error) {
+	key := new(contract.APIKey)
+
+	err := s.Collection.FindOne(ctx, bson.M{"prefix": prefix}).Decode(key)
+
+	if err == mongo.ErrNoDocuments {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// Touch ...
+func (s *APIKeyStore) Touch(ctx context.Context, id string, at time.Time) error {
+	_, err := s.Collection.UpdateOne(ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{"last_used_at": at}},
+	)
+
+	return err
+}
+
+// Insert ...
+func (s *APIKeyStore) Insert(ctx context.Context, key *contract.APIKey) error {
+	_, err := s.Collection.InsertOne(ctx, key)
+	return err
+}
+
+// Revoke ...
+func (s *APIKeyStore) Revoke(ctx context.Context, id string) error {
+	_, err := s.Collection.DeleteOne(ctx, bson.M{"_id": id})
+	return err
+}
@@ -0,0 +1,50 @@
+package mongodb_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/raafvargas/wrapit/auth"
+	"github.com/raafvargas/wrapit/configuration"
+	"github.com/raafvargas/wrapit/mongodb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIKeyStore(t *testing.T) {
+	cfg := new(configuration.Config)
+
+	if err := configuration.FromYAML("../tests/config.yaml", cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := mongodb.Connect(context.Background(), uuid.New().String(), cfg.Mongo)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := mongodb.NewAPIKeyStore(client.Database(cfg.Mongo.Database).Collection("api_keys"))
+	assert.NoError(t, store.EnsureIndexes(context.Background()))
+
+	raw, key, err := auth.GenerateAPIKey("test", "partner")
+	assert.NoError(t, err)
+	assert.NoError(t, store.Insert(context.Background(), key))
+
+	handler := auth.NewAPIKeyHandler(&auth.APIKeyConfig{}, store)
+
+	found, err := handler.Validate(context.Background(), raw)
+	assert.NoError(t, err)
+	assert.Equal(t, "partner", found.Subject)
+
+	assert.NoError(t, store.Touch(context.Background(), key.ID, time.Now()))
+	assert.NoError(t, store.Revoke(context.Background(), key.ID))
+
+	missing, err := store.Find(context.Background(), key.Prefix)
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
+
+	_, err = handler.Validate(context.Background(), raw)
+	assert.Equal(t, auth.ErrInvalidAPIKey, err)
+}
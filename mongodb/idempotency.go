@@ -0,0 +1,104 @@
+package mongodb
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/raafvargas/wrapit/contract"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// IdempotencyStore keeps the idempotent responses until they expire through a TTL index
+type IdempotencyStore struct {
+	Collection *mongo.Collection
+}
+
+// NewIdempotencyStore ...
+func NewIdempotencyStore(collection *mongo.Collection) *IdempotencyStore {
+	return &IdempotencyStore{
+		Collection: collection,
+	}
+}
+
+// EnsureIndexes creates the TTL index removing expired records
+func (s *IdempotencyStore) EnsureIndexes(ctx context.Context) error {
+	_, err := s.Collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.M{"expires_at": 1},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+
+	return err
+}
+
+// Begin reserves the key, taking over records that expired before the TTL monitor removed them
+func (s *IdempotencyStore) Begin(ctx context.Context, record *contract.IdempotencyRecord) (*contract.IdempotencyRecord, bool, error) {
+	_, err := s.Collection.InsertOne(ctx, record)
+
+	if err == nil {
+		return nil, true, nil
+	}
+
+	if !isDuplicateKey(err) {
+		return nil, false, err
+	}
+
+	now := time.Now()
+	existing := new(contract.IdempotencyRecord)
+
+	err = s.Collection.FindOne(ctx, bson.M{
+		"_id":        record.Key,
+		"expires_at": bson.M{"$gt": now},
+	}).Decode(existing)
+
+	if err == nil {
+		return existing, false, nil
+	}
+
+	if err != mongo.ErrNoDocuments {
+		return nil, false, err
+	}
+
+	result, err := s.Collection.ReplaceOne(ctx, bson.M{
+		"_id":        record.Key,
+		"expires_at": bson.M{"$lte": now},
+	}, record)
+
+	if err != nil {
+		return nil, false, err
+	}
+
+	if result.MatchedCount == 0 {
+		// another request took over the expired record first
+		return nil, false, contract.NewError(http.StatusConflict,
+			"a request with the same idempotency key is in progress")
+	}
+
+	return nil, true, nil
+}
+
+// Complete ...
+func (s *IdempotencyStore) Complete(ctx context.Context, record *contract.IdempotencyRecord) error {
+	_, err := s.Collection.ReplaceOne(ctx, bson.M{"_id": record.Key}, record)
+	return err
+}
+
+// Release ...
+func (s *IdempotencyStore) Release(ctx context.Context, key string) error {
+	_, err := s.Collection.DeleteOne(ctx, bson.M{"_id": key})
+	return err
+}
+
+func isDuplicateKey(err error) bool {
+	if writeErr, ok := err.(mongo.WriteException); ok {
+		for _, e := range writeErr.WriteErrors {
+			if e.Code == 11000 {
+				return true
+			}
+		}
+	}
+
+	return false
+}
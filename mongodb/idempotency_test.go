@@ -0,0 +1,73 @@
+package mongodb_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/raafvargas/wrapit/configuration"
+	"github.com/raafvargas/wrapit/contract"
+	"github.com/raafvargas/wrapit/mongodb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotencyStore(t *testing.T) {
+	cfg := new(configuration.Config)
+
+	if err := configuration.FromYAML("../tests/config.yaml", cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := mongodb.Connect(context.Background(), uuid.New().String(), cfg.Mongo)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := mongodb.NewIdempotencyStore(client.Database(cfg.Mongo.Database).Collection("idempotency"))
+	assert.NoError(t, store.EnsureIndexes(context.Background()))
+
+	record := &contract.IdempotencyRecord{
+		Key:         uuid.New().String(),
+		Fingerprint: "fingerprint",
+		ExpiresAt:   time.Now().Add(time.Minute),
+	}
+
+	_, reserved, err := store.Begin(context.Background(), record)
+	assert.NoError(t, err)
+	assert.True(t, reserved)
+
+	existing, reserved, err := store.Begin(context.Background(), record)
+	assert.NoError(t, err)
+	assert.False(t, reserved)
+	assert.False(t, existing.Completed)
+
+	record.Completed = true
+	record.Status = http.StatusCreated
+	assert.NoError(t, store.Complete(context.Background(), record))
+
+	existing, _, err = store.Begin(context.Background(), record)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusCreated, existing.Status)
+
+	assert.NoError(t, store.Release(context.Background(), record.Key))
+
+	expired := &contract.IdempotencyRecord{
+		Key:         uuid.New().String(),
+		Fingerprint: "fingerprint",
+		Completed:   true,
+		ExpiresAt:   time.Now().Add(-time.Minute),
+	}
+
+	_, reserved, err = store.Begin(context.Background(), expired)
+	assert.NoError(t, err)
+	assert.True(t, reserved)
+
+	expired.ExpiresAt = time.Now().Add(time.Minute)
+
+	_, reserved, err = store.Begin(context.Background(), expired)
+	assert.NoError(t, err)
+	assert.True(t, reserved)
+}
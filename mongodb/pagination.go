@@ -0,0 +1,260 @@
+package mongodb
+
+import (
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/raafvargas/wrapit/contract"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+var (
+	// ErrInvalidCursor is returned for cursors that weren't encoded for the requested sort
+	ErrInvalidCursor = contract.NewError(http.StatusUnprocessableEntity, "invalid cursor")
+)
+
+// FilterType converts a filter param into the value stored in the documents
+type FilterType func(value string) (interface{}, error)
+
+var (
+	// IntFilter ...
+	IntFilter FilterType = func(value string) (interface{}, error) {
+		return strconv.ParseInt(value, 10, 64)
+	}
+
+	// FloatFilter ...
+	FloatFilter FilterType = func(value string) (interface{}, error) {
+		return strconv.ParseFloat(value, 64)
+	}
+
+	// BoolFilter ...
+	BoolFilter FilterType = func(value string) (interface{}, error) {
+		return strconv.ParseBool(value)
+	}
+
+	// TimeFilter parses RFC 3339 times
+	TimeFilter FilterType = func(value string) (interface{}, error) {
+		return time.Parse(time.RFC3339, value)
+	}
+
+	// ObjectIDFilter ...
+	ObjectIDFilter FilterType = func(value string) (interface{}, error) {
+		return primitive.ObjectIDFromHex(value)
+	}
+)
+
+// FindOptions converts the list request into limit, skip and sort options for page-based pagination
+func FindOptions(request *contract.ListRequest) *options.FindOptions {
+	opts := options.Find()
+
+	if request.Limit > 0 {
+		opts.SetLimit(int64(request.Limit))
+	}
+
+	if skip := request.Offset(); skip > 0 {
+		opts.SetSkip(int64(skip))
+	}
+
+	if len(request.Sort) > 0 {
+		opts.SetSort(sortDocument(request.Sort))
+	}
+
+	return opts
+}
+
+// CursorFindOptions converts the list request into limit and sort options for cursor-based pagination.
+// Every page, including the first one, is sorted by the requested fields followed by _id as tiebreaker,
+// the same keys EncodeCursor stores and Filter seeks after.
+func CursorFindOptions(request *contract.ListRequest) *options.FindOptions {
+	opts := options.Find().SetSort(sortDocument(cursorSort(request)))
+
+	if request.Limit > 0 {
+		opts.SetLimit(int64(request.Limit))
+	}
+
+	return opts
+}
+
+// Filter converts the list filters into equality matches and comma separated values into $in matches.
+// Filters are strings unless their type is declared in types. The cursor matches the documents
+// after it in the CursorFindOptions order. Invalid filters and cursors return a 422 contract.Error.
+func Filter(request *contract.ListRequest, types map[string]FilterType) (bson.M, error) {
+	filter := bson.M{}
+	err := contract.NewError(http.StatusUnprocessableEntity)
+
+	for field, value := range request.Filters {
+		values := []interface{}{}
+
+		for _, item := range strings.Split(value, ",") {
+			converted, e := filterValue(types[field], item)
+
+			if e != nil {
+				err.Messages = append(err.Messages, "invalid value for field "+field)
+				err.Details = append(err.Details, contract.FieldError{Field: field, Tag: "type", Value: item})
+				break
+			}
+
+			values = append(values, converted)
+		}
+
+		if len(values) > 1 {
+			filter[field] = bson.M{"$in": values}
+			continue
+		}
+
+		if len(values) == 1 {
+			filter[field] = values[0]
+		}
+	}
+
+	if len(err.Details) > 0 {
+		return nil, err
+	}
+
+	if request.Cursor != "" {
+		seek, err := seekFilter(request)
+
+		if err != nil {
+			return nil, err
+		}
+
+		filter["$or"] = seek
+	}
+
+	return filter, nil
+}
+
+// EncodeCursor returns the cursor pointing after the document, holding its sort fields and _id
+func EncodeCursor(request *contract.ListRequest, document interface{}) (string, error) {
+	raw, err := bson.Marshal(document)
+
+	if err != nil {
+		return "", err
+	}
+
+	values := bson.D{}
+
+	for _, field := range cursorSort(request) {
+		value, err := bson.Raw(raw).LookupErr(strings.Split(field.Field, ".")...)
+
+		if err != nil {
+			return "", err
+		}
+
+		values = append(values, bson.E{Key: field.Field, Value: value})
+	}
+
+	data, err := bson.Marshal(values)
+
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// DecodeCursor returns the sort fields and _id the cursor points after. Cursors are client
+// input, values holding documents or arrays are rejected so they can't inject query operators.
+func DecodeCursor(cursor string) (bson.D, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	values := bson.D{}
+
+	if err := bson.Unmarshal(data, &values); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	for _, value := range values {
+		switch value.Value.(type) {
+		case primitive.D, primitive.M, primitive.A:
+			return nil, ErrInvalidCursor
+		}
+	}
+
+	return values, nil
+}
+
+// seekFilter matches the documents after the cursor, e.g. a > x or (a = x and _id > y)
+func seekFilter(request *contract.ListRequest) (bson.A, error) {
+	values, err := DecodeCursor(request.Cursor)
+
+	if err != nil {
+		return nil, err
+	}
+
+	sort := cursorSort(request)
+
+	if len(values) != len(sort) {
+		return nil, ErrInvalidCursor
+	}
+
+	seek := bson.A{}
+
+	for i, field := range sort {
+		if values[i].Key != field.Field {
+			return nil, ErrInvalidCursor
+		}
+
+		condition := bson.M{}
+
+		for _, previous := range values[:i] {
+			condition[previous.Key] = previous.Value
+		}
+
+		operator := "$gt"
+
+		if field.Descending {
+			operator = "$lt"
+		}
+
+		condition[field.Field] = bson.M{operator: values[i].Value}
+		seek = append(seek, condition)
+	}
+
+	return seek, nil
+}
+
+func cursorSort(request *contract.ListRequest) []contract.SortField {
+	sort := append([]contract.SortField{}, request.Sort...)
+
+	for _, field := range sort {
+		if field.Field == "_id" {
+			return sort
+		}
+	}
+
+	return append(sort, contract.SortField{Field: "_id"})
+}
+
+func sortDocument(fields []contract.SortField) bson.D {
+	sort := bson.D{}
+
+	for _, field := range fields {
+		order := 1
+
+		if field.Descending {
+			order = -1
+		}
+
+		sort = append(sort, bson.E{Key: field.Field, Value: order})
+	}
+
+	return sort
+}
+
+func filterValue(filterType FilterType, value string) (interface{}, error) {
+	if filterType == nil {
+		return value, nil
+	}
+
+	return filterType(value)
+}
@@ -0,0 +1,98 @@
+package mongodb_test
+
+import (
+	"encoding/base64"
+	"testing"
+
+	"github.com/raafvargas/wrapit/contract"
+	"github.com/raafvargas/wrapit/mongodb"
+	"github.com/stretchr/testify/assert"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestFindOptions(t *testing.T) {
+	opts := mongodb.FindOptions(&contract.ListRequest{
+		Page:  3,
+		Limit: 10,
+		Sort:  []contract.SortField{{Field: "created_at", Descending: true}, {Field: "name"}},
+	})
+
+	assert.Equal(t, int64(10), *opts.Limit)
+	assert.Equal(t, int64(20), *opts.Skip)
+	assert.Equal(t, bson.D{{Key: "created_at", Value: -1}, {Key: "name", Value: 1}}, opts.Sort)
+}
+
+func TestCursorFindOptions(t *testing.T) {
+	for _, cursor := range []string{"", "abc"} {
+		opts := mongodb.CursorFindOptions(&contract.ListRequest{
+			Page:   3,
+			Limit:  10,
+			Cursor: cursor,
+			Sort:   []contract.SortField{{Field: "name", Descending: true}},
+		})
+
+		assert.Nil(t, opts.Skip)
+		assert.Equal(t, int64(10), *opts.Limit)
+		assert.Equal(t, bson.D{{Key: "name", Value: -1}, {Key: "_id", Value: 1}}, opts.Sort)
+	}
+}
+
+func TestFilter(t *testing.T) {
+	id := primitive.NewObjectID()
+	sort := []contract.SortField{{Field: "name", Descending: true}}
+
+	cursor, err := mongodb.EncodeCursor(&contract.ListRequest{Sort: sort}, bson.M{"_id": id, "name": "john"})
+	assert.NoError(t, err)
+
+	filter, err := mongodb.Filter(&contract.ListRequest{
+		Cursor:  cursor,
+		Sort:    sort,
+		Filters: map[string]string{"status": "active", "type": "a,b", "age": "30"},
+	}, map[string]mongodb.FilterType{"age": mongodb.IntFilter})
+
+	assert.NoError(t, err)
+	assert.Equal(t, bson.M{
+		"status": "active",
+		"type":   bson.M{"$in": []interface{}{"a", "b"}},
+		"age":    int64(30),
+		"$or": bson.A{
+			bson.M{"name": bson.M{"$lt": "john"}},
+			bson.M{"name": "john", "_id": bson.M{"$gt": id}},
+		},
+	}, filter)
+}
+
+func TestFilterInvalid(t *testing.T) {
+	_, err := mongodb.Filter(&contract.ListRequest{
+		Filters: map[string]string{"age": "old"},
+	}, map[string]mongodb.FilterType{"age": mongodb.IntFilter})
+
+	assert.Error(t, err)
+
+	cursor, err := mongodb.EncodeCursor(&contract.ListRequest{}, bson.M{"_id": 1, "name": "john"})
+	assert.NoError(t, err)
+
+	_, err = mongodb.Filter(&contract.ListRequest{
+		Cursor: cursor,
+		Sort:   []contract.SortField{{Field: "name"}},
+	}, nil)
+
+	assert.Equal(t, mongodb.ErrInvalidCursor, err)
+
+	_, err = mongodb.DecodeCursor("not a cursor")
+	assert.Equal(t, mongodb.ErrInvalidCursor, err)
+}
+
+func TestFilterCursorOperators(t *testing.T) {
+	for _, value := range []interface{}{bson.M{"$ne": nil}, bson.A{1, 2}} {
+		data, err := bson.Marshal(bson.D{{Key: "_id", Value: value}})
+		assert.NoError(t, err)
+
+		_, err = mongodb.Filter(&contract.ListRequest{
+			Cursor: base64.RawURLEncoding.EncodeToString(data),
+		}, nil)
+
+		assert.Equal(t, mongodb.ErrInvalidCursor, err)
+	}
+}
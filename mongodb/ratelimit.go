@@ -0,0 +1,88 @@
+package mongodb
+
+import (
+	"context"
+	"math"
+
+	"github.com/raafvargas/wrapit/contract"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// RateLimitStore shares the token buckets between replicas.
+// Buckets are updated atomically with an update pipeline and require MongoDB 4.2+.
+type RateLimitStore struct {
+	Collection *mongo.Collection
+}
+
+type rateLimitBucket struct {
+	Tokens  float64 `bson:"tokens"`
+	Allowed bool    `bson:"allowed"`
+}
+
+// NewRateLimitStore ...
+func NewRateLimitStore(collection *mongo.Collection) *RateLimitStore {
+	return &RateLimitStore{
+		Collection: collection,
+	}
+}
+
+// EnsureIndexes creates the TTL index removing idle buckets
+func (s *RateLimitStore) EnsureIndexes(ctx context.Context) error {
+	_, err := s.Collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.M{"expires_at": 1},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+
+	return err
+}
+
+// Take ...
+func (s *RateLimitStore) Take(ctx context.Context, key string, rate contract.Rate) (contract.RateLimitResult, error) {
+	burst := float64(rate.Burst)
+	ttl := int64(math.Ceil(burst/math.Max(rate.Limit, 1e-9))) * 1000
+
+	elapsed := bson.M{"$divide": bson.A{
+		bson.M{"$subtract": bson.A{"$$NOW", bson.M{"$ifNull": bson.A{"$updated", "$$NOW"}}}},
+		1000,
+	}}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			"tokens": bson.M{"$min": bson.A{
+				burst,
+				bson.M{"$add": bson.A{
+					bson.M{"$ifNull": bson.A{"$tokens", burst}},
+					bson.M{"$multiply": bson.A{elapsed, rate.Limit}},
+				}},
+			}},
+		}}},
+		{{Key: "$set", Value: bson.M{
+			"allowed": bson.M{"$gte": bson.A{"$tokens", 1}},
+		}}},
+		{{Key: "$set", Value: bson.M{
+			"tokens": bson.M{"$cond": bson.A{
+				"$allowed",
+				bson.M{"$subtract": bson.A{"$tokens", 1}},
+				"$tokens",
+			}},
+			"updated":    "$$NOW",
+			"expires_at": bson.M{"$add": bson.A{"$$NOW", ttl}},
+		}}},
+	}
+
+	bucket := new(rateLimitBucket)
+
+	err := s.Collection.FindOneAndUpdate(ctx, bson.M{"_id": key}, pipeline,
+		options.FindOneAndUpdate().
+			SetUpsert(true).
+			SetReturnDocument(options.After),
+	).Decode(bucket)
+
+	if err != nil {
+		return contract.RateLimitResult{}, err
+	}
+
+	return rate.Result(bucket.Tokens, bucket.Allowed), nil
+}
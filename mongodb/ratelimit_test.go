@@ -0,0 +1,46 @@
+package mongodb_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/raafvargas/wrapit/configuration"
+	"github.com/raafvargas/wrapit/contract"
+	"github.com/raafvargas/wrapit/mongodb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitStore(t *testing.T) {
+	cfg := new(configuration.Config)
+
+	if err := configuration.FromYAML("../tests/config.yaml", cfg); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := mongodb.Connect(context.Background(), uuid.New().String(), cfg.Mongo)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	store := mongodb.NewRateLimitStore(client.Database(cfg.Mongo.Database).Collection("ratelimit"))
+	assert.NoError(t, store.EnsureIndexes(context.Background()))
+
+	key := uuid.New().String()
+	rate := contract.Rate{Limit: 0.1, Burst: 2}
+
+	result, err := store.Take(context.Background(), key, rate)
+	assert.NoError(t, err)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 1, result.Remaining)
+
+	result, err = store.Take(context.Background(), key, rate)
+	assert.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	result, err = store.Take(context.Background(), key, rate)
+	assert.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.True(t, result.RetryAfter > 0)
+}
@@ -3,8 +3,11 @@ package mongodb
 import (
 	"context"
 	"errors"
+	"net/http"
 	"reflect"
+	"strings"
 
+	"github.com/raafvargas/wrapit/contract"
 	"github.com/sirupsen/logrus"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -13,6 +16,12 @@ import (
 var (
 	// ErrCannotSetID ...
 	ErrCannotSetID = errors.New("cannot set id property")
+
+	// ErrCannotSetVersion ...
+	ErrCannotSetVersion = errors.New("cannot set version property")
+
+	// ErrVersionConflict is returned by updates of a stale version, responded by the api as 412
+	ErrVersionConflict = contract.NewError(http.StatusPreconditionFailed, "document was modified by another request")
 )
 
 // Repository ...
@@ -24,26 +33,72 @@ type Repository interface {
 
 // MongoRepository ...
 type MongoRepository struct {
-	idProperty   string
-	documentType reflect.Type
+	idProperty      string
+	versionProperty string
+	versionField    string
+	documentType    reflect.Type
 
 	Collection *mongo.Collection
 }
 
+// RepositoryOption ...
+type RepositoryOption func(*MongoRepository)
+
+// WithVersionProperty enables optimistic concurrency using the int64 property as the document version.
+// Inserts start at version 1 and updates only apply to the version the document carries,
+// incrementing it, or fail with ErrVersionConflict.
+func WithVersionProperty(property string) RepositoryOption {
+	return func(r *MongoRepository) {
+		r.versionProperty = property
+	}
+}
+
 // NewMongoRepository ...
-func NewMongoRepository(idProperty string, documentType reflect.Type, collection *mongo.Collection) *MongoRepository {
-	return &MongoRepository{
+func NewMongoRepository(idProperty string, documentType reflect.Type, collection *mongo.Collection, options ...RepositoryOption) *MongoRepository {
+	repository := &MongoRepository{
 		idProperty:   idProperty,
 		documentType: documentType,
 		Collection:   collection,
 	}
+
+	for _, o := range options {
+		o(repository)
+	}
+
+	if repository.versionProperty != "" && documentType.Kind() == reflect.Struct {
+		if field, ok := documentType.FieldByName(repository.versionProperty); ok {
+			repository.versionField = bsonName(field)
+		}
+	}
+
+	return repository
 }
 
 // Insert ...
 func (r *MongoRepository) Insert(ctx context.Context, document interface{}) error {
+	// initial is the version set on the document, reset when the insert fails
+	var initial reflect.Value
+
+	if r.versionProperty != "" {
+		version, err := r.versionValue(document)
+
+		if err != nil {
+			return err
+		}
+
+		if version.Int() == 0 {
+			version.SetInt(1)
+			initial = version
+		}
+	}
+
 	result, err := r.Collection.InsertOne(ctx, document)
 
 	if err != nil {
+		if initial.IsValid() {
+			initial.SetInt(0)
+		}
+
 		return err
 	}
 
@@ -62,6 +117,10 @@ func (r *MongoRepository) Insert(ctx context.Context, document interface{}) erro
 
 // Update ...
 func (r *MongoRepository) Update(ctx context.Context, id interface{}, document interface{}) error {
+	if r.versionProperty != "" {
+		return r.updateVersion(ctx, id, document)
+	}
+
 	_, err := r.Collection.UpdateOne(ctx, bson.M{
 		"_id": id,
 	}, bson.M{"$set": document})
@@ -69,6 +128,72 @@ func (r *MongoRepository) Update(ctx context.Context, id interface{}, document i
 	return err
 }
 
+func (r *MongoRepository) updateVersion(ctx context.Context, id interface{}, document interface{}) error {
+	version, err := r.versionValue(document)
+
+	if err != nil {
+		return err
+	}
+
+	current := version.Int()
+	version.SetInt(current + 1)
+
+	result, err := r.Collection.UpdateOne(ctx, bson.M{
+		"_id":          id,
+		r.versionField: current,
+	}, bson.M{"$set": document})
+
+	if err == nil && result.MatchedCount == 1 {
+		return nil
+	}
+
+	version.SetInt(current)
+
+	if err != nil {
+		return err
+	}
+
+	count, err := r.Collection.CountDocuments(ctx, bson.M{"_id": id})
+
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return mongo.ErrNoDocuments
+	}
+
+	return ErrVersionConflict
+}
+
+func (r *MongoRepository) versionValue(document interface{}) (reflect.Value, error) {
+	value := reflect.ValueOf(document)
+
+	if value.Kind() != reflect.Ptr {
+		return reflect.Value{}, ErrCannotSetVersion
+	}
+
+	field := value.Elem().FieldByName(r.versionProperty)
+
+	if !field.IsValid() || !field.CanSet() || field.Kind() != reflect.Int64 {
+		logrus.WithField("document", document).
+			Warnf("property %s is invalid or cannot be set", r.versionProperty)
+		return reflect.Value{}, ErrCannotSetVersion
+	}
+
+	return field, nil
+}
+
+func bsonName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("bson"), ",")[0]
+
+	if name == "" {
+		return strings.ToLower(field.Name)
+	}
+
+	return name
+}
+
 // FindByID ...
 func (r *MongoRepository) FindByID(ctx context.Context, id interface{}) (interface{}, error) {
 	doc := reflect.New(r.documentType).Interface()
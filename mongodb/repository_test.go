@@ -2,6 +2,7 @@ package mongodb_test
 
 import (
 	"context"
+	"net/http"
 	"reflect"
 	"testing"
 
@@ -84,3 +85,53 @@ func (s *RepositoryTestSuite) TestRepositoryInvalidID() {
 	s.assert.Error(err)
 	s.assert.EqualError(err, mongodb.ErrCannotSetID.Error())
 }
+
+type VersionedTestDocument struct {
+	ID      primitive.ObjectID `bson:"_id,omitempty"`
+	Value   string             `bson:"value"`
+	Version int64              `bson:"version"`
+}
+
+func (s *RepositoryTestSuite) TestRepositoryVersion() {
+	repository := mongodb.NewMongoRepository("ID", reflect.TypeOf(VersionedTestDocument{}), s.collection,
+		mongodb.WithVersionProperty("Version"))
+
+	doc := &VersionedTestDocument{Value: uuid.New().String()}
+
+	err := repository.Insert(context.Background(), doc)
+	s.assert.NoError(err)
+	s.assert.Equal(int64(1), doc.Version)
+
+	stale := *doc
+
+	doc.Value = uuid.New().String()
+	err = repository.Update(context.Background(), doc.ID, doc)
+	s.assert.NoError(err)
+	s.assert.Equal(int64(2), doc.Version)
+
+	stale.Value = uuid.New().String()
+	err = repository.Update(context.Background(), stale.ID, &stale)
+	s.assert.Equal(mongodb.ErrVersionConflict, err)
+	s.assert.Equal(int64(1), stale.Version)
+
+	err = repository.Update(context.Background(), primitive.NewObjectID(), &stale)
+	s.assert.Equal(mongo.ErrNoDocuments, err)
+
+	duplicate := &VersionedTestDocument{ID: doc.ID, Value: uuid.New().String()}
+
+	err = repository.Insert(context.Background(), duplicate)
+	s.assert.Error(err)
+	s.assert.Equal(int64(0), duplicate.Version)
+}
+
+func TestRepositoryInvalidVersionProperty(t *testing.T) {
+	repository := mongodb.NewMongoRepository("ID", reflect.TypeOf(MongoTestDocument{}), nil,
+		mongodb.WithVersionProperty("Version"))
+
+	err := repository.Update(context.Background(), primitive.NewObjectID(), &MongoTestDocument{})
+	assert.Equal(t, mongodb.ErrCannotSetVersion, err)
+}
+
+func TestErrVersionConflict(t *testing.T) {
+	assert.Equal(t, http.StatusPreconditionFailed, mongodb.ErrVersionConflict.Code)
+}
@@ -0,0 +1,24 @@
+package rabbitmq
+
+import (
+	"context"
+)
+
+// Broadcaster pushes messages to connected clients, such as api.Hub
+type Broadcaster interface {
+	Publish(topic, event string, data interface{}) error
+}
+
+// NewBroadcastHandler forwards the consumed messages to the broadcaster topic.
+// The event name is the cloud event type when the message is a cloud event.
+func NewBroadcastHandler(broadcaster Broadcaster, topic string) *DefaultHandler {
+	return NewDefaultHandler(func(ctx context.Context, message interface{}) error {
+		event := ""
+
+		if cloudEvent, ok := CloudEventFromContext(ctx); ok {
+			event = cloudEvent.Type
+		}
+
+		return broadcaster.Publish(topic, event, message)
+	})
+}
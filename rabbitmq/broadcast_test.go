@@ -0,0 +1,40 @@
+package rabbitmq_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/raafvargas/wrapit/rabbitmq"
+	"github.com/stretchr/testify/assert"
+)
+
+type broadcasterMock struct {
+	topic string
+	event string
+	data  interface{}
+}
+
+func (b *broadcasterMock) Publish(topic, event string, data interface{}) error {
+	b.topic, b.event, b.data = topic, event, data
+	return nil
+}
+
+func TestBroadcastHandler(t *testing.T) {
+	broadcaster := new(broadcasterMock)
+	handler := rabbitmq.NewBroadcastHandler(broadcaster, "orders")
+
+	ctx := rabbitmq.ContextWithCloudEvent(context.Background(), &rabbitmq.CloudEvent{Type: "order.created"})
+
+	assert.NoError(t, handler.Handle(ctx, map[string]string{"id": "1"}))
+	assert.Equal(t, "orders", broadcaster.topic)
+	assert.Equal(t, "order.created", broadcaster.event)
+	assert.Equal(t, map[string]string{"id": "1"}, broadcaster.data)
+}
+
+func TestBroadcastHandlerPlainMessage(t *testing.T) {
+	broadcaster := new(broadcasterMock)
+	handler := rabbitmq.NewBroadcastHandler(broadcaster, "orders")
+
+	assert.NoError(t, handler.Handle(context.Background(), "message"))
+	assert.Equal(t, "", broadcaster.event)
+}
@@ -0,0 +1,230 @@
+package rabbitmq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/raafvargas/wrapit/contract"
+	"github.com/streadway/amqp"
+)
+
+// CloudEventMode ...
+type CloudEventMode int
+
+const (
+	// CloudEventsDisabled publishes plain JSON messages
+	CloudEventsDisabled CloudEventMode = iota
+	// CloudEventsBinary publishes the event attributes as ce- headers
+	CloudEventsBinary
+	// CloudEventsStructured publishes the whole event as a JSON envelope
+	CloudEventsStructured
+)
+
+var (
+	// CloudEventsSpecVersion ...
+	CloudEventsSpecVersion = "1.0"
+
+	// CloudEventsHeaderPrefix ...
+	CloudEventsHeaderPrefix = "ce-"
+
+	// CloudEventsContentType ...
+	CloudEventsContentType = "application/cloudevents+json"
+
+	// DefaultCloudEventSource ...
+	DefaultCloudEventSource = "wrapit"
+
+	// ErrInvalidCloudEvent ...
+	ErrInvalidCloudEvent = errors.New("invalid cloud event")
+)
+
+type cloudEventContextKey struct{}
+
+type outgoingCloudEventContextKey struct{}
+
+// CloudEvent ...
+type CloudEvent struct {
+	ID              string     `json:"id"`
+	Source          string     `json:"source"`
+	SpecVersion     string     `json:"specversion"`
+	Type            string     `json:"type"`
+	Subject         string     `json:"subject,omitempty"`
+	Time            *time.Time `json:"time,omitempty"`
+	DataContentType string     `json:"datacontenttype,omitempty"`
+}
+
+type structuredCloudEvent struct {
+	CloudEvent
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// ContextWithCloudEvent stores the event received by a consumer
+func ContextWithCloudEvent(ctx context.Context, event *CloudEvent) context.Context {
+	return context.WithValue(ctx, cloudEventContextKey{}, event)
+}
+
+// CloudEventFromContext returns the event received by a consumer
+func CloudEventFromContext(ctx context.Context) (*CloudEvent, bool) {
+	event, ok := ctx.Value(cloudEventContextKey{}).(*CloudEvent)
+	return event, ok
+}
+
+// ContextWithOutgoingCloudEvent overrides the attributes of the next published event
+func ContextWithOutgoingCloudEvent(ctx context.Context, event *CloudEvent) context.Context {
+	return context.WithValue(ctx, outgoingCloudEventContextKey{}, event)
+}
+
+// EncodeCloudEvent writes the event and its data into the publishing using the given mode
+func EncodeCloudEvent(event *CloudEvent, mode CloudEventMode, data []byte, publishing *amqp.Publishing) error {
+	publishing.MessageId = event.ID
+
+	if event.Time != nil {
+		publishing.Timestamp = *event.Time
+	}
+
+	switch mode {
+	case CloudEventsBinary:
+		if publishing.Headers == nil {
+			publishing.Headers = make(amqp.Table)
+		}
+
+		publishing.Headers[CloudEventsHeaderPrefix+"specversion"] = event.SpecVersion
+		publishing.Headers[CloudEventsHeaderPrefix+"id"] = event.ID
+		publishing.Headers[CloudEventsHeaderPrefix+"source"] = event.Source
+		publishing.Headers[CloudEventsHeaderPrefix+"type"] = event.Type
+		if event.Time != nil {
+			publishing.Headers[CloudEventsHeaderPrefix+"time"] = event.Time.Format(time.RFC3339Nano)
+		}
+
+		if event.Subject != "" {
+			publishing.Headers[CloudEventsHeaderPrefix+"subject"] = event.Subject
+		}
+
+		publishing.ContentType = event.DataContentType
+		publishing.Body = data
+	case CloudEventsStructured:
+		body, err := json.Marshal(&structuredCloudEvent{
+			CloudEvent: *event,
+			Data:       data,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		publishing.ContentType = CloudEventsContentType
+		publishing.Body = body
+	default:
+		publishing.Body = data
+	}
+
+	return nil
+}
+
+// DecodeCloudEvent reads a binary or structured cloud event from the delivery.
+// It returns a nil event and the original body when the delivery isn't a cloud event.
+func DecodeCloudEvent(delivery amqp.Delivery) (*CloudEvent, []byte, error) {
+	if strings.HasPrefix(delivery.ContentType, CloudEventsContentType) {
+		structured := new(structuredCloudEvent)
+
+		if err := json.Unmarshal(delivery.Body, structured); err != nil {
+			return nil, nil, ErrInvalidCloudEvent
+		}
+
+		if structured.SpecVersion == "" || structured.ID == "" {
+			return nil, nil, ErrInvalidCloudEvent
+		}
+
+		return &structured.CloudEvent, structured.Data, nil
+	}
+
+	headers := amqpHeaders(delivery.Headers)
+
+	if headers.Get(CloudEventsHeaderPrefix+"specversion") == "" {
+		return nil, delivery.Body, nil
+	}
+
+	event := &CloudEvent{
+		ID:              headers.Get(CloudEventsHeaderPrefix + "id"),
+		Source:          headers.Get(CloudEventsHeaderPrefix + "source"),
+		SpecVersion:     headers.Get(CloudEventsHeaderPrefix + "specversion"),
+		Type:            headers.Get(CloudEventsHeaderPrefix + "type"),
+		Subject:         headers.Get(CloudEventsHeaderPrefix + "subject"),
+		DataContentType: delivery.ContentType,
+	}
+
+	if value := headers.Get(CloudEventsHeaderPrefix + "time"); value != "" {
+		t, err := time.Parse(time.RFC3339Nano, value)
+
+		if err != nil {
+			return nil, nil, ErrInvalidCloudEvent
+		}
+
+		event.Time = &t
+	}
+
+	if event.ID == "" {
+		return nil, nil, ErrInvalidCloudEvent
+	}
+
+	return event, delivery.Body, nil
+}
+
+type amqpHeaders amqp.Table
+
+func (h amqpHeaders) Get(key string) string {
+	value, _ := h[key].(string)
+	return value
+}
+
+func (p *Producer) newCloudEvent(ctx context.Context, exchange string) *CloudEvent {
+	now := time.Now().UTC()
+
+	event := &CloudEvent{
+		ID:              uuid.New().String(),
+		Source:          p.Source,
+		SpecVersion:     CloudEventsSpecVersion,
+		Type:            exchange,
+		Time:            &now,
+		DataContentType: "application/json",
+	}
+
+	if event.Source == "" {
+		event.Source = DefaultCloudEventSource
+	}
+
+	if eventType, ok := contract.EventTypeFromContext(ctx); ok {
+		event.Type = eventType
+	}
+
+	override, ok := ctx.Value(outgoingCloudEventContextKey{}).(*CloudEvent)
+
+	if !ok {
+		return event
+	}
+
+	if override.ID != "" {
+		event.ID = override.ID
+	}
+
+	if override.Source != "" {
+		event.Source = override.Source
+	}
+
+	if override.Type != "" {
+		event.Type = override.Type
+	}
+
+	if override.Subject != "" {
+		event.Subject = override.Subject
+	}
+
+	if override.Time != nil {
+		event.Time = override.Time
+	}
+
+	return event
+}
@@ -0,0 +1,100 @@
+package rabbitmq_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/raafvargas/wrapit/rabbitmq"
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCloudEventBinary(t *testing.T) {
+	now := time.Now().UTC()
+
+	event := &rabbitmq.CloudEvent{
+		ID:              "id",
+		Source:          "source",
+		SpecVersion:     rabbitmq.CloudEventsSpecVersion,
+		Type:            "type",
+		Subject:         "subject",
+		Time:            &now,
+		DataContentType: "application/json",
+	}
+
+	publishing := amqp.Publishing{}
+
+	err := rabbitmq.EncodeCloudEvent(event, rabbitmq.CloudEventsBinary, []byte(`{"a":"b"}`), &publishing)
+	assert.NoError(t, err)
+	assert.Equal(t, "id", publishing.Headers["ce-id"])
+
+	decoded, data, err := rabbitmq.DecodeCloudEvent(amqp.Delivery{
+		Headers:     publishing.Headers,
+		ContentType: publishing.ContentType,
+		Body:        publishing.Body,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a":"b"}`, string(data))
+	assert.Equal(t, event.ID, decoded.ID)
+	assert.Equal(t, event.Subject, decoded.Subject)
+	assert.True(t, event.Time.Equal(*decoded.Time))
+}
+
+func TestCloudEventStructured(t *testing.T) {
+	event := &rabbitmq.CloudEvent{
+		ID:          "id",
+		Source:      "source",
+		SpecVersion: rabbitmq.CloudEventsSpecVersion,
+		Type:        "type",
+	}
+
+	publishing := amqp.Publishing{}
+
+	err := rabbitmq.EncodeCloudEvent(event, rabbitmq.CloudEventsStructured, []byte(`{"a":"b"}`), &publishing)
+	assert.NoError(t, err)
+	assert.Equal(t, rabbitmq.CloudEventsContentType, publishing.ContentType)
+
+	decoded, data, err := rabbitmq.DecodeCloudEvent(amqp.Delivery{
+		ContentType: publishing.ContentType,
+		Body:        publishing.Body,
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, `{"a":"b"}`, string(data))
+	assert.Equal(t, event.Type, decoded.Type)
+	assert.Nil(t, decoded.Time)
+	assert.NotContains(t, string(publishing.Body), `"time"`)
+}
+
+func TestCloudEventPlainMessage(t *testing.T) {
+	event, data, err := rabbitmq.DecodeCloudEvent(amqp.Delivery{
+		ContentType: "application/json",
+		Body:        []byte(`{}`),
+	})
+
+	assert.NoError(t, err)
+	assert.Nil(t, event)
+	assert.Equal(t, `{}`, string(data))
+}
+
+func TestCloudEventInvalid(t *testing.T) {
+	_, _, err := rabbitmq.DecodeCloudEvent(amqp.Delivery{
+		ContentType: rabbitmq.CloudEventsContentType,
+		Body:        []byte(`invalid`),
+	})
+
+	assert.EqualError(t, err, rabbitmq.ErrInvalidCloudEvent.Error())
+}
+
+func TestCloudEventFromContext(t *testing.T) {
+	_, ok := rabbitmq.CloudEventFromContext(context.Background())
+	assert.False(t, ok)
+
+	ctx := rabbitmq.ContextWithCloudEvent(context.Background(), &rabbitmq.CloudEvent{ID: "id"})
+
+	event, ok := rabbitmq.CloudEventFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "id", event.ID)
+}
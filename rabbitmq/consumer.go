@@ -8,6 +8,7 @@ import (
 	"os/signal"
 	"reflect"
 
+	"github.com/raafvargas/wrapit/requestid"
 	"github.com/raafvargas/wrapit/tracing"
 	"github.com/sirupsen/logrus"
 	"github.com/streadway/amqp"
@@ -32,12 +33,14 @@ type AMQPConsumer interface {
 // Consumer ...
 type Consumer struct {
 	Shutdown chan os.Signal
+	Signals  []os.Signal
 	stopped  chan error
 
 	connection *RabbitConnection
 
-	tracer trace.Tracer
-	logger *logrus.Logger
+	tracer          trace.Tracer
+	logger          *logrus.Logger
+	principalSecret []byte
 
 	Queue        string
 	Exchange     string
@@ -57,6 +60,7 @@ func NewConsumer(connection *RabbitConnection, options ...ConsumerOption) (*Cons
 		Asynchronous: 10,
 		Prefetch:     100,
 		Shutdown:     make(chan os.Signal, 1),
+		Signals:      []os.Signal{os.Interrupt},
 	}
 
 	for _, o := range options {
@@ -78,13 +82,17 @@ func NewConsumer(connection *RabbitConnection, options ...ConsumerOption) (*Cons
 	return consumer, nil
 }
 
-// Consume ...
+// Consume blocks until one of the Signals, the Shutdown channel or the cancellation
+// of ctx stops it, waiting for the messages being handled.
 func (c *Consumer) Consume(ctx context.Context) error {
 	if err := c.ensureQueue(ctx); err != nil {
 		return err
 	}
 
-	signal.Notify(c.Shutdown, os.Interrupt)
+	if len(c.Signals) > 0 {
+		signal.Notify(c.Shutdown, c.Signals...)
+		defer signal.Stop(c.Shutdown)
+	}
 
 	if err := c.connection.Channel.Qos(c.Prefetch, 0, false); err != nil {
 		return err
@@ -113,19 +121,34 @@ func (c *Consumer) createConsumer(ctx context.Context, queue string) {
 	for {
 		select {
 		case message := <-delivery:
-			sem.Acquire(ctx, 1)
+			if err := sem.Acquire(ctx, 1); err != nil {
+				message.Nack(false, true)
+				c.stop(sem)
+				return
+			}
+
 			go func() {
 				defer sem.Release(1)
 				c.handleDelivery(message)
 			}()
 		case sig := <-c.Shutdown:
 			logrus.Infof("got sig %s. stopping consumers", sig.String())
-			c.stopped <- nil
+			c.stop(sem)
+			return
+		case <-ctx.Done():
+			logrus.Info("context done. stopping consumers")
+			c.stop(sem)
 			return
 		}
 	}
 }
 
+// stop waits for the messages being handled
+func (c *Consumer) stop(sem *semaphore.Weighted) {
+	sem.Acquire(context.Background(), c.Asynchronous)
+	c.stopped <- nil
+}
+
 func (c *Consumer) handleDelivery(delivery amqp.Delivery) {
 	c.logger.WithField("queue", c.Queue).WithField("exchange", delivery.Exchange).
 		Infof("start consuming message %s", delivery.MessageId)
@@ -139,21 +162,52 @@ func (c *Consumer) handleDelivery(delivery amqp.Delivery) {
 
 	ctx := tracing.AMQPPropagator.Extract(context.Background(), tracing.AMQPSupplier(delivery.Headers))
 
+	id := tracing.AMQPSupplier(delivery.Headers).Get(requestid.MetadataKey)
+
+	if !requestid.Valid(id) {
+		id = requestid.New()
+	}
+
+	ctx = requestid.NewContext(ctx, id)
+	logger := c.logger.WithField(requestid.LogField, id)
+
+	if c.principalSecret != nil {
+		principalCtx, err := ExtractPrincipal(ctx, delivery.Headers, c.principalSecret)
+
+		if err != nil {
+			logger.WithError(err).Warn("dropping message principal")
+		}
+
+		ctx = principalCtx
+	}
+
 	ctx, span := c.tracer.Start(ctx, ConsumerOperationName,
 		trace.WithSpanKind(trace.SpanKindConsumer))
 	defer span.End()
 
+	event, body, err := DecodeCloudEvent(delivery)
+
+	if event != nil {
+		ctx = ContextWithCloudEvent(ctx, event)
+		span.SetAttribute("cloudevents.id", event.ID)
+		span.SetAttribute("cloudevents.type", event.Type)
+	}
+
 	message := reflect.New(c.MessageType).Interface()
 
-	if err := json.Unmarshal(delivery.Body, message); err != nil {
+	if err == nil {
+		err = json.Unmarshal(body, message)
+	}
+
+	if err != nil {
 		span.RecordError(ctx, err)
-		c.logger.WithField("type", c.MessageType.String()).
+		logger.WithField("type", c.MessageType.String()).
 			WithField("body", string(delivery.Body)).
 			Warn("coldn't unmarshal message body")
 
 		if err := delivery.Reject(false); err != nil {
 			span.RecordError(ctx, err)
-			c.logger.WithError(err).Error("nack error")
+			logger.WithError(err).Error("nack error")
 		}
 
 		if c.OnError != nil {
@@ -166,12 +220,12 @@ func (c *Consumer) handleDelivery(delivery amqp.Delivery) {
 	if err := c.Handler.Handle(ctx, message); err != nil {
 		span.RecordError(ctx, err)
 
-		c.logger.WithError(err).
+		logger.WithError(err).
 			Error("consumer handler error")
 
 		if err := delivery.Reject(false); err != nil {
 			span.RecordError(ctx, err)
-			c.logger.WithError(err).Error("nack error")
+			logger.WithError(err).Error("nack error")
 		}
 
 		if c.OnError != nil {
@@ -184,12 +238,12 @@ func (c *Consumer) handleDelivery(delivery amqp.Delivery) {
 	if err := delivery.Ack(false); err != nil {
 		span.RecordError(ctx, err)
 
-		c.logger.WithError(err).
+		logger.WithError(err).
 			Error("ack error")
 		return
 	}
 
-	c.logger.Infof("finished message %s", delivery.MessageId)
+	logger.Infof("finished message %s", delivery.MessageId)
 }
 
 func (c *Consumer) ensureQueue(ctx context.Context) error {
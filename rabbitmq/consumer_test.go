@@ -8,8 +8,10 @@ import (
 	"testing"
 
 	"github.com/google/uuid"
+	"github.com/raafvargas/wrapit/auth"
 	"github.com/raafvargas/wrapit/configuration"
 	"github.com/raafvargas/wrapit/rabbitmq"
+	"github.com/raafvargas/wrapit/requestid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 )
@@ -112,6 +114,141 @@ func (s *ConsumerTestSuite) TestConsumer() {
 	<-done
 }
 
+func (s *ConsumerTestSuite) TestConsumerCloudEvents() {
+	message := struct {
+		A string `json:"a"`
+	}{
+		A: "B",
+	}
+
+	events := make(chan *rabbitmq.CloudEvent, 1)
+
+	consumer, err := rabbitmq.NewConsumer(
+		s.connection,
+		rabbitmq.WithQueue(s.queueName),
+		rabbitmq.WithExchange(s.exchangeName),
+		rabbitmq.WithMessageType(reflect.TypeOf(message)),
+		rabbitmq.WithHandler(
+			rabbitmq.NewDefaultHandler(
+				func(ctx context.Context, message interface{}) error {
+					event, _ := rabbitmq.CloudEventFromContext(ctx)
+					events <- event
+					return nil
+				},
+			),
+		),
+	)
+	s.assert.NoError(err)
+
+	go consumer.Consume(context.Background())
+
+	conn, _ := rabbitmq.NewConnection(s.config.RabbitMQ)
+	producer := rabbitmq.NewProducer(conn,
+		rabbitmq.WithCloudEvents(rabbitmq.CloudEventsStructured),
+		rabbitmq.WithSource("tests"),
+	)
+	defer conn.Close()
+
+	ctx := rabbitmq.ContextWithOutgoingCloudEvent(context.Background(), &rabbitmq.CloudEvent{
+		Subject: "subject",
+	})
+
+	err = producer.Publish(ctx, s.exchangeName, message)
+	s.assert.NoError(err)
+
+	event := <-events
+	s.assert.NotNil(event)
+	s.assert.Equal("tests", event.Source)
+	s.assert.Equal("subject", event.Subject)
+	s.assert.Equal(s.exchangeName, event.Type)
+
+	consumer.Shutdown <- os.Interrupt
+}
+
+func (s *ConsumerTestSuite) TestConsumerRequestID() {
+	message := struct {
+		A string `json:"a"`
+	}{
+		A: "B",
+	}
+
+	ids := make(chan string, 1)
+
+	consumer, err := rabbitmq.NewConsumer(
+		s.connection,
+		rabbitmq.WithQueue(s.queueName),
+		rabbitmq.WithExchange(s.exchangeName),
+		rabbitmq.WithMessageType(reflect.TypeOf(message)),
+		rabbitmq.WithHandler(
+			rabbitmq.NewDefaultHandler(
+				func(ctx context.Context, message interface{}) error {
+					ids <- requestid.FromContext(ctx)
+					return nil
+				},
+			),
+		),
+	)
+	s.assert.NoError(err)
+
+	go consumer.Consume(context.Background())
+
+	conn, _ := rabbitmq.NewConnection(s.config.RabbitMQ)
+	producer := rabbitmq.NewProducer(conn)
+	defer conn.Close()
+
+	ctx := requestid.NewContext(context.Background(), "request-id")
+
+	err = producer.Publish(ctx, s.exchangeName, message)
+	s.assert.NoError(err)
+
+	s.assert.Equal("request-id", <-ids)
+
+	consumer.Shutdown <- os.Interrupt
+}
+
+func (s *ConsumerTestSuite) TestConsumerPrincipal() {
+	message := struct {
+		A string `json:"a"`
+	}{
+		A: "B",
+	}
+
+	subjects := make(chan string, 1)
+
+	consumer, err := rabbitmq.NewConsumer(
+		s.connection,
+		rabbitmq.WithQueue(s.queueName),
+		rabbitmq.WithExchange(s.exchangeName),
+		rabbitmq.WithMessageType(reflect.TypeOf(message)),
+		rabbitmq.WithConsumerPrincipalSecret([]byte("secret")),
+		rabbitmq.WithHandler(
+			rabbitmq.NewDefaultHandler(
+				func(ctx context.Context, message interface{}) error {
+					principal, _ := auth.FromContext(ctx)
+					subjects <- principal.Subject
+					return nil
+				},
+			),
+		),
+	)
+	s.assert.NoError(err)
+
+	go consumer.Consume(context.Background())
+
+	conn, _ := rabbitmq.NewConnection(s.config.RabbitMQ)
+	producer := rabbitmq.NewProducer(conn, rabbitmq.WithPrincipalSecret([]byte("secret")))
+	defer conn.Close()
+
+	ctx := auth.NewContext(context.Background(), &auth.Principal{Subject: "user"})
+
+	err = producer.Publish(ctx, s.exchangeName, message)
+	s.assert.NoError(err)
+
+	s.assert.Equal("user", <-subjects)
+
+	consumer.Shutdown <- os.Interrupt
+}
+
 func (s *ConsumerTestSuite) TestConsumerPanic() {
 	message := struct {
 		A string `json:"a"`
@@ -2,12 +2,16 @@ package rabbitmq
 
 import (
 	"context"
+	"os"
 	"reflect"
 )
 
 // ConsumerOption ...
 type ConsumerOption func(*Consumer)
 
+// ProducerOption ...
+type ProducerOption func(*Producer)
+
 // WithPrefetch ...
 func WithPrefetch(prefetch int) ConsumerOption {
 	return func(c *Consumer) {
@@ -29,6 +33,13 @@ func WithMessageType(t reflect.Type) ConsumerOption {
 	}
 }
 
+// WithSignals sets the signals stopping the consumer. No signals leaves it to the Consume context.
+func WithSignals(signals ...os.Signal) ConsumerOption {
+	return func(c *Consumer) {
+		c.Signals = signals
+	}
+}
+
 // WithHandler ...
 func WithHandler(handler AMQPHandler) ConsumerOption {
 	return func(c *Consumer) {
@@ -56,3 +67,33 @@ func WithExchange(exchange string) ConsumerOption {
 		c.Exchange = exchange
 	}
 }
+
+// WithCloudEvents ...
+func WithCloudEvents(mode CloudEventMode) ProducerOption {
+	return func(p *Producer) {
+		p.CloudEvents = mode
+	}
+}
+
+// WithSource ...
+func WithSource(source string) ProducerOption {
+	return func(p *Producer) {
+		p.Source = source
+	}
+}
+
+// WithPrincipalSecret propagates the subject and scopes of the auth principal of the
+// publish context in a header signed with secret. Consumers need the same secret.
+func WithPrincipalSecret(secret []byte) ProducerOption {
+	return func(p *Producer) {
+		p.principalSecret = secret
+	}
+}
+
+// WithConsumerPrincipalSecret restores the principals signed with secret by the producers,
+// available to handlers through auth.FromContext. Unsigned, forged and expired principals are dropped.
+func WithConsumerPrincipalSecret(secret []byte) ConsumerOption {
+	return func(c *Consumer) {
+		c.principalSecret = secret
+	}
+}
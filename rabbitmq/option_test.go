@@ -80,3 +80,27 @@ func TestWithOnError(t *testing.T) {
 	consumer.OnError(context.Background(), nil)
 	assert.True(t, <-called)
 }
+
+func TestWithCloudEvents(t *testing.T) {
+	producer := &rabbitmq.Producer{}
+
+	rabbitmq.WithCloudEvents(rabbitmq.CloudEventsBinary)(producer)
+
+	assert.Equal(t, rabbitmq.CloudEventsBinary, producer.CloudEvents)
+}
+
+func TestWithSource(t *testing.T) {
+	producer := &rabbitmq.Producer{}
+
+	rabbitmq.WithSource("source")(producer)
+
+	assert.Equal(t, "source", producer.Source)
+}
+
+func TestWithSignals(t *testing.T) {
+	consumer := &rabbitmq.Consumer{}
+
+	rabbitmq.WithSignals()(consumer)
+
+	assert.Empty(t, consumer.Signals)
+}
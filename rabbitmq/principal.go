@@ -0,0 +1,117 @@
+package rabbitmq
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/raafvargas/wrapit/auth"
+	"github.com/streadway/amqp"
+)
+
+var (
+	// DefaultPrincipalTTL bounds the propagated principals without an expiry
+	DefaultPrincipalTTL = time.Hour
+
+	// ErrInvalidPrincipal ...
+	ErrInvalidPrincipal = errors.New("invalid principal header signature")
+
+	// ErrExpiredPrincipal ...
+	ErrExpiredPrincipal = errors.New("expired principal header")
+)
+
+// propagatedPrincipal is the signed subset of the principal crossing the broker
+type propagatedPrincipal struct {
+	Subject   string   `json:"sub"`
+	Scopes    []string `json:"scopes,omitempty"`
+	ExpiresAt int64    `json:"exp"`
+}
+
+// InjectPrincipal signs the subject, scopes and expiry of the auth principal of ctx
+// into the message headers
+func InjectPrincipal(ctx context.Context, headers amqp.Table, secret []byte) error {
+	principal, ok := auth.FromContext(ctx)
+
+	if !ok {
+		return nil
+	}
+
+	expiresAt := time.Now().Add(DefaultPrincipalTTL)
+
+	if !principal.ExpiresAt.IsZero() && principal.ExpiresAt.Before(expiresAt) {
+		expiresAt = principal.ExpiresAt
+	}
+
+	data, err := json.Marshal(&propagatedPrincipal{
+		Subject:   principal.Subject,
+		Scopes:    principal.Scopes,
+		ExpiresAt: expiresAt.Unix(),
+	})
+
+	if err != nil {
+		return err
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString(data)
+	headers[auth.PrincipalHeader] = payload + "." + base64.RawURLEncoding.EncodeToString(signPrincipal(payload, secret))
+
+	return nil
+}
+
+// ExtractPrincipal verifies the principal header signed by InjectPrincipal and stores the
+// principal in ctx. Messages without the header return ctx unchanged.
+func ExtractPrincipal(ctx context.Context, headers amqp.Table, secret []byte) (context.Context, error) {
+	value, ok := headers[auth.PrincipalHeader].(string)
+
+	if !ok {
+		return ctx, nil
+	}
+
+	parts := strings.Split(value, ".")
+
+	if len(parts) != 2 {
+		return ctx, ErrInvalidPrincipal
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[1])
+
+	if err != nil || !hmac.Equal(signature, signPrincipal(parts[0], secret)) {
+		return ctx, ErrInvalidPrincipal
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(parts[0])
+
+	if err != nil {
+		return ctx, ErrInvalidPrincipal
+	}
+
+	propagated := new(propagatedPrincipal)
+
+	if err := json.Unmarshal(data, propagated); err != nil {
+		return ctx, ErrInvalidPrincipal
+	}
+
+	expiresAt := time.Unix(propagated.ExpiresAt, 0)
+
+	if time.Now().After(expiresAt) {
+		return ctx, ErrExpiredPrincipal
+	}
+
+	return auth.NewContext(ctx, &auth.Principal{
+		Subject:   propagated.Subject,
+		Scopes:    propagated.Scopes,
+		ExpiresAt: expiresAt,
+		Claims:    map[string]interface{}{},
+	}), nil
+}
+
+func signPrincipal(payload string, secret []byte) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
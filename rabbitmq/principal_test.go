@@ -0,0 +1,77 @@
+package rabbitmq_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/raafvargas/wrapit/auth"
+	"github.com/raafvargas/wrapit/rabbitmq"
+	"github.com/streadway/amqp"
+	"github.com/stretchr/testify/assert"
+)
+
+var principalSecret = []byte("secret")
+
+func TestPrincipalPropagation(t *testing.T) {
+	headers := amqp.Table{}
+
+	assert.NoError(t, rabbitmq.InjectPrincipal(context.Background(), headers, principalSecret))
+	assert.Empty(t, headers)
+
+	ctx := auth.NewContext(context.Background(), auth.NewPrincipal(map[string]interface{}{
+		"sub":    "user",
+		"tenant": "acme",
+		"scope":  "read",
+		"email":  "user@example.com",
+	}))
+
+	assert.NoError(t, rabbitmq.InjectPrincipal(ctx, headers, principalSecret))
+	assert.NoError(t, headers.Validate())
+	assert.NotContains(t, headers[auth.PrincipalHeader], "user@example.com")
+
+	ctx, err := rabbitmq.ExtractPrincipal(context.Background(), headers, principalSecret)
+	assert.NoError(t, err)
+
+	principal, ok := auth.FromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "user", principal.Subject)
+	assert.Equal(t, []string{"read"}, principal.Scopes)
+	assert.Empty(t, principal.Tenant)
+	assert.Empty(t, principal.Claims)
+}
+
+func TestExtractPrincipalInvalid(t *testing.T) {
+	headers := amqp.Table{}
+	ctx := auth.NewContext(context.Background(), &auth.Principal{Subject: "user"})
+	assert.NoError(t, rabbitmq.InjectPrincipal(ctx, headers, principalSecret))
+
+	tests := map[string]amqp.Table{
+		"unsigned":     {auth.PrincipalHeader: `{"sub":"admin"}`},
+		"other secret": headers,
+	}
+
+	for name, headers := range tests {
+		ctx, err := rabbitmq.ExtractPrincipal(context.Background(), headers, []byte("other"))
+		assert.Equal(t, rabbitmq.ErrInvalidPrincipal, err, name)
+
+		_, ok := auth.FromContext(ctx)
+		assert.False(t, ok, name)
+	}
+}
+
+func TestExtractPrincipalExpired(t *testing.T) {
+	headers := amqp.Table{}
+	ctx := auth.NewContext(context.Background(), &auth.Principal{
+		Subject:   "user",
+		ExpiresAt: time.Now().Add(-time.Minute),
+	})
+
+	assert.NoError(t, rabbitmq.InjectPrincipal(ctx, headers, principalSecret))
+
+	ctx, err := rabbitmq.ExtractPrincipal(context.Background(), headers, principalSecret)
+	assert.Equal(t, rabbitmq.ErrExpiredPrincipal, err)
+
+	_, ok := auth.FromContext(ctx)
+	assert.False(t, ok)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 
+	"github.com/raafvargas/wrapit/requestid"
 	"github.com/raafvargas/wrapit/tracing"
 	"github.com/streadway/amqp"
 	"go.opentelemetry.io/otel/api/global"
@@ -22,16 +23,26 @@ type AMQPProducer interface {
 
 // Producer ...
 type Producer struct {
-	connection *RabbitConnection
-	tracer     trace.Tracer
+	connection      *RabbitConnection
+	tracer          trace.Tracer
+	principalSecret []byte
+
+	CloudEvents CloudEventMode
+	Source      string
 }
 
 // NewProducer ...
-func NewProducer(connection *RabbitConnection) *Producer {
-	return &Producer{
+func NewProducer(connection *RabbitConnection, options ...ProducerOption) *Producer {
+	producer := &Producer{
 		connection: connection,
 		tracer:     global.Tracer(TracingTracerName),
 	}
+
+	for _, o := range options {
+		o(producer)
+	}
+
+	return producer
 }
 
 // Publish ...
@@ -43,6 +54,17 @@ func (p *Producer) Publish(ctx context.Context, exchange string, message interfa
 	headers := make(tracing.AMQPSupplier)
 	tracing.AMQPPropagator.Inject(ctx, headers)
 
+	if id := requestid.FromContext(ctx); id != "" {
+		headers.Set(requestid.MetadataKey, id)
+	}
+
+	if p.principalSecret != nil {
+		if err := InjectPrincipal(ctx, amqp.Table(headers), p.principalSecret); err != nil {
+			span.RecordError(ctx, err)
+			return err
+		}
+	}
+
 	data, err := json.Marshal(message)
 
 	if err != nil {
@@ -52,10 +74,18 @@ func (p *Producer) Publish(ctx context.Context, exchange string, message interfa
 
 	span.SetAttribute("message.body", string(data))
 
-	return p.connection.Channel.Publish(exchange, "", true, false, amqp.Publishing{
+	publishing := amqp.Publishing{
 		DeliveryMode: 2,
-		Body:         data,
 		ContentType:  "application/json",
 		Headers:      amqp.Table(headers),
-	})
+	}
+
+	if p.CloudEvents == CloudEventsDisabled {
+		publishing.Body = data
+	} else if err := EncodeCloudEvent(p.newCloudEvent(ctx, exchange), p.CloudEvents, data, &publishing); err != nil {
+		span.RecordError(ctx, err)
+		return err
+	}
+
+	return p.connection.Channel.Publish(exchange, "", true, false, publishing)
 }
@@ -0,0 +1,80 @@
+package requestid
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	// HeaderName is the HTTP header used to carry the request id
+	HeaderName = "X-Request-ID"
+
+	// MetadataKey is the gRPC metadata and AMQP header key used to carry the request id
+	MetadataKey = "x-request-id"
+
+	// LogField ...
+	LogField = "request_id"
+
+	// MaxLength bounds the ids accepted from clients
+	MaxLength = 128
+)
+
+type contextKey struct{}
+
+// New ...
+func New() string {
+	return uuid.New().String()
+}
+
+// Valid reports whether a client supplied id is at most MaxLength characters of
+// letters, digits, '.', '_' and '-'
+func Valid(id string) bool {
+	if id == "" || len(id) > MaxLength {
+		return false
+	}
+
+	for _, c := range id {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '.', c == '_', c == '-':
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// NewContext ...
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request id stored in ctx or an empty string
+func FromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// LogrusHook adds the request id to entries logged with WithContext
+type LogrusHook struct{}
+
+// Levels ...
+func (LogrusHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire ...
+func (LogrusHook) Fire(entry *logrus.Entry) error {
+	if id := FromContext(entry.Context); id != "" {
+		entry.Data[LogField] = id
+	}
+
+	return nil
+}
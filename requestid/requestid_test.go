@@ -0,0 +1,41 @@
+package requestid_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/raafvargas/wrapit/requestid"
+	"github.com/sirupsen/logrus/hooks/test"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContext(t *testing.T) {
+	assert.Empty(t, requestid.FromContext(context.Background()))
+
+	ctx := requestid.NewContext(context.Background(), "id")
+	assert.Equal(t, "id", requestid.FromContext(ctx))
+}
+
+func TestValid(t *testing.T) {
+	assert.True(t, requestid.Valid(requestid.New()))
+	assert.True(t, requestid.Valid("trace_1.2-A"))
+	assert.False(t, requestid.Valid(""))
+	assert.False(t, requestid.Valid("id with spaces"))
+	assert.False(t, requestid.Valid("id\ninjected"))
+	assert.False(t, requestid.Valid(strings.Repeat("a", requestid.MaxLength+1)))
+}
+
+func TestNew(t *testing.T) {
+	assert.NotEqual(t, requestid.New(), requestid.New())
+}
+
+func TestLogrusHook(t *testing.T) {
+	logger, hook := test.NewNullLogger()
+	logger.AddHook(requestid.LogrusHook{})
+
+	ctx := requestid.NewContext(context.Background(), "id")
+	logger.WithContext(ctx).Info("message")
+
+	assert.Equal(t, "id", hook.LastEntry().Data[requestid.LogField])
+}